@@ -0,0 +1,299 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterExpectationKind("AdvisoryLock", func(query string) bool {
+		kind, ok := advisoryKind(query)
+		return ok && kind != advisoryUnlock
+	})
+	RegisterExpectationKind("AdvisoryUnlock", func(query string) bool {
+		kind, ok := advisoryKind(query)
+		return ok && kind == advisoryUnlock
+	})
+}
+
+// advisory lock functions of postgres and mysql
+const (
+	advisoryBlocking = iota // pg_advisory_lock, waits until acquired
+	advisoryTry             // pg_try_advisory_lock, does not wait
+	advisoryTimed           // GET_LOCK, waits up to a timeout
+	advisoryUnlock          // pg_advisory_unlock and RELEASE_LOCK
+)
+
+// advisoryKind tells which advisory lock function is called
+// by the query, if any, without allocating
+func advisoryKind(query string) (int, bool) {
+	fn := selectedFunction(query)
+	switch {
+	case hasPrefixFold(fn, "pg_advisory_unlock"), strings.EqualFold(fn, "RELEASE_LOCK"):
+		return advisoryUnlock, true
+	case hasPrefixFold(fn, "pg_try_advisory_"):
+		return advisoryTry, true
+	case hasPrefixFold(fn, "pg_advisory_"):
+		return advisoryBlocking, true
+	case strings.EqualFold(fn, "GET_LOCK"):
+		return advisoryTimed, true
+	}
+	return 0, false
+}
+
+// selectedFunction returns the name of the function, which the
+// query selects, like "pg_advisory_lock" of "SELECT pg_advisory_lock(1)"
+func selectedFunction(query string) string {
+	if !hasPrefixFold(query, "SELECT ") {
+		return ""
+	}
+	fn := query[len("SELECT "):]
+	i := strings.IndexByte(fn, '(')
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(fn[:i])
+}
+
+// functionParams returns literal parameters of the selected function
+func functionParams(query string) []string {
+	i, j := strings.IndexByte(query, '('), strings.LastIndexByte(query, ')')
+	if i < 0 || j < i {
+		return nil
+	}
+	params := strings.Split(query[i+1:j], ",")
+	for i, p := range params {
+		params[i] = strings.Trim(strings.TrimSpace(p), `'"`)
+	}
+	return params
+}
+
+// advisoryParam returns the n-th parameter of the advisory lock
+// function call, given either as an argument or as a literal
+func advisoryParam(query string, args []driver.Value, n int) (driver.Value, bool) {
+	params := functionParams(query)
+	if n >= len(params) {
+		return nil, false
+	}
+	if !isPlaceholder(params[n]) {
+		return params[n], true
+	}
+
+	// arguments are bound to placeholders in order
+	arg := 0
+	for _, p := range params[:n] {
+		if isPlaceholder(p) {
+			arg++
+		}
+	}
+	if arg < len(args) {
+		return args[arg], true
+	}
+	return nil, false
+}
+
+// isPlaceholder tells whether the literal parameter is a placeholder
+func isPlaceholder(param string) bool {
+	return len(param) > 0 && strings.IndexByte("$?:@", param[0]) >= 0
+}
+
+// ExpectedAdvisoryLock is used to manage an advisory lock
+// acquisition expected by ExpectAdvisoryLock
+type ExpectedAdvisoryLock struct {
+	clock  *Clock
+	key    driver.Value
+	heldBy time.Duration
+	err    error
+}
+
+// ExpectAdvisoryLock expects an advisory lock of the key to be acquired,
+// either by postgres pg_advisory_lock family of functions or by mysql
+// GET_LOCK. The key is matched to the first parameter of the function,
+// given either as an argument or as a literal:
+//
+//	sqlmock.ExpectAdvisoryLock(mock, 42)
+//	db.QueryRow("SELECT pg_try_advisory_lock($1)", 42).Scan(&acquired)
+//
+// The lock is acquired at once, unless WillBeHeldFor simulates another
+// session holding it. Until an advisory lock is expected this way, lock
+// acquisitions are matched to ExpectQuery and ExpectExec expectations.
+func ExpectAdvisoryLock(mock Sqlmock, key driver.Value) *ExpectedAdvisoryLock {
+	e := &ExpectedAdvisoryLock{clock: mock.Clock(), key: key}
+	mock.ExpectCustom("AdvisoryLock", e)
+	return e
+}
+
+// WillBeHeldFor simulates contention, the lock is held by another session
+// for d of the simulated time, since it was requested. Functions trying to
+// acquire the lock without waiting return false. The blocking ones wait for
+// the clock of the mock to be advanced by d, while GET_LOCK waits for its
+// timeout only and returns 0, if the timeout is shorter:
+//
+//	sqlmock.ExpectAdvisoryLock(mock, "jobs").WillBeHeldFor(time.Minute)
+//	go func() {
+//		for mock.Clock().Waiting() == 0 {
+//			time.Sleep(time.Millisecond)
+//		}
+//		mock.Clock().Advance(time.Minute)
+//	}()
+func (e *ExpectedAdvisoryLock) WillBeHeldFor(d time.Duration) *ExpectedAdvisoryLock {
+	e.heldBy = d
+	return e
+}
+
+// WillReturnError allows to set an error for the lock acquisition
+func (e *ExpectedAdvisoryLock) WillReturnError(err error) *ExpectedAdvisoryLock {
+	e.err = err
+	return e
+}
+
+// Match implements CustomExpectation
+func (e *ExpectedAdvisoryLock) Match(query string, args []driver.Value) bool {
+	return advisoryKeyMatches(e.key, query, args)
+}
+
+// Handle implements CustomExpectation
+func (e *ExpectedAdvisoryLock) Handle(query string, args []driver.Value) (driver.Result, driver.Rows, error) {
+	if e.err != nil {
+		return nil, nil, e.err
+	}
+
+	fn := selectedFunction(query)
+	kind, _ := advisoryKind(query)
+	switch kind {
+	case advisoryTry:
+		return advisoryRows(fn, advisoryStatus(fn, e.heldBy <= 0))
+	case advisoryTimed:
+		acquired := true
+		if timeout, ok := lockTimeout(query, args); ok && timeout < e.heldBy {
+			<-e.clock.After(timeout)
+			acquired = false
+		} else {
+			<-e.clock.After(e.heldBy)
+		}
+		return advisoryRows(fn, advisoryStatus(fn, acquired))
+	}
+
+	// pg_advisory_lock returns void
+	<-e.clock.After(e.heldBy)
+	return advisoryRows(fn, nil)
+}
+
+func (e *ExpectedAdvisoryLock) String() string {
+	msg := fmt.Sprintf("expecting advisory lock of key %+v", e.key)
+	if e.heldBy > 0 {
+		msg += fmt.Sprintf(", held by another session for %s", e.heldBy)
+	}
+	return msg
+}
+
+// ExpectedAdvisoryUnlock is used to manage an advisory lock
+// release expected by ExpectAdvisoryUnlock
+type ExpectedAdvisoryUnlock struct {
+	key     driver.Value
+	notHeld bool
+	err     error
+}
+
+// ExpectAdvisoryUnlock expects an advisory lock of the key to be released,
+// either by postgres pg_advisory_unlock family of functions or by mysql
+// RELEASE_LOCK. The lock is released successfully, unless WillReturnNotHeld
+// is set. Until an advisory unlock is expected this way, lock releases are
+// matched to ExpectQuery and ExpectExec expectations.
+func ExpectAdvisoryUnlock(mock Sqlmock, key driver.Value) *ExpectedAdvisoryUnlock {
+	e := &ExpectedAdvisoryUnlock{key: key}
+	mock.ExpectCustom("AdvisoryUnlock", e)
+	return e
+}
+
+// WillReturnNotHeld simulates the lock not being held by the session,
+// so that the release returns false, or 0 for RELEASE_LOCK
+func (e *ExpectedAdvisoryUnlock) WillReturnNotHeld() *ExpectedAdvisoryUnlock {
+	e.notHeld = true
+	return e
+}
+
+// WillReturnError allows to set an error for the lock release
+func (e *ExpectedAdvisoryUnlock) WillReturnError(err error) *ExpectedAdvisoryUnlock {
+	e.err = err
+	return e
+}
+
+// Match implements CustomExpectation
+func (e *ExpectedAdvisoryUnlock) Match(query string, args []driver.Value) bool {
+	return advisoryKeyMatches(e.key, query, args)
+}
+
+// Handle implements CustomExpectation
+func (e *ExpectedAdvisoryUnlock) Handle(query string, args []driver.Value) (driver.Result, driver.Rows, error) {
+	if e.err != nil {
+		return nil, nil, e.err
+	}
+	fn := selectedFunction(query)
+	return advisoryRows(fn, advisoryStatus(fn, !e.notHeld))
+}
+
+func (e *ExpectedAdvisoryUnlock) String() string {
+	return fmt.Sprintf("expecting advisory unlock of key %+v", e.key)
+}
+
+// advisoryKeyMatches compares the key to the first function parameter
+func advisoryKeyMatches(key driver.Value, query string, args []driver.Value) bool {
+	actual, ok := advisoryParam(query, args, 0)
+	if !ok {
+		return false
+	}
+	if literal, ok := actual.(string); ok {
+		return literal == fmt.Sprint(key)
+	}
+	return CompareArgs([]driver.Value{key}, []driver.Value{actual}) == nil
+}
+
+// lockTimeout returns the timeout of GET_LOCK, a negative
+// one means to wait infinitely
+func lockTimeout(query string, args []driver.Value) (time.Duration, bool) {
+	param, ok := advisoryParam(query, args, 1)
+	if !ok {
+		return 0, false
+	}
+
+	var seconds float64
+	switch v := param.(type) {
+	case int64:
+		seconds = float64(v)
+	case float64:
+		seconds = v
+	case string:
+		var err error
+		if seconds, err = strconv.ParseFloat(v, 64); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	if seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// advisoryRows returns the single value of the function as rows,
+// so that it is the same, whether the function is queried or executed
+func advisoryRows(fn string, value driver.Value) (driver.Result, driver.Rows, error) {
+	return driver.ResultNoRows, NewRows([]string{fn}).AddRow(value), nil
+}
+
+// advisoryStatus returns whether the function succeeded, postgres
+// returns booleans, while mysql returns 1 or 0
+func advisoryStatus(fn string, ok bool) driver.Value {
+	switch {
+	case hasPrefixFold(fn, "pg_"):
+		return ok
+	case ok:
+		return int64(1)
+	}
+	return int64(0)
+}
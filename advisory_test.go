@@ -0,0 +1,121 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAdvisoryLocks(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	ExpectAdvisoryLock(mock, 42)
+	ExpectAdvisoryLock(mock, 42).WillBeHeldFor(time.Minute)
+	ExpectAdvisoryUnlock(mock, 42)
+	ExpectAdvisoryLock(mock, "jobs")
+	ExpectAdvisoryUnlock(mock, "jobs").WillReturnNotHeld()
+
+	var acquired bool
+	if err := db.QueryRow("SELECT pg_try_advisory_lock($1)", 42).Scan(&acquired); err != nil || !acquired {
+		t.Errorf("expected the lock to be acquired, but got %v and error: %v", acquired, err)
+	}
+	if err := db.QueryRow("SELECT pg_try_advisory_lock(42)").Scan(&acquired); err != nil || acquired {
+		t.Errorf("expected the contended lock not to be acquired, but got %v and error: %v", acquired, err)
+	}
+	if _, err := db.Exec("SELECT pg_advisory_unlock($1)", 42); err != nil {
+		t.Errorf("error '%s' was not expected while unlocking", err)
+	}
+
+	var status int64
+	if err := db.QueryRow("SELECT GET_LOCK('jobs', 10)").Scan(&status); err != nil || status != 1 {
+		t.Errorf("expected the lock to be acquired, but got %d and error: %v", status, err)
+	}
+	if err := db.QueryRow("SELECT RELEASE_LOCK(?)", "jobs").Scan(&status); err != nil || status != 0 {
+		t.Errorf("expected the lock not to be held, but got %d and error: %v", status, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestAdvisoryLockByExpectQuery(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	mock.ExpectQuery("SELECT pg_advisory_lock").WithArgs(42).WillReturnRows(NewRows([]string{"pg_advisory_lock"}).AddRow(""))
+
+	var locked string
+	if err := db.QueryRow("SELECT pg_advisory_lock($1)", 42).Scan(&locked); err != nil {
+		t.Errorf("error '%s' was not expected, since the lock is expected by ExpectQuery", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestAdvisoryLockBlocksOnClock(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	ExpectAdvisoryLock(mock, 7).WillBeHeldFor(time.Minute)
+	ExpectAdvisoryLock(mock, "jobs").WillBeHeldFor(time.Minute)
+
+	advance := func(d time.Duration) {
+		for mock.Clock().Waiting() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		mock.Clock().Advance(d)
+	}
+
+	go advance(time.Minute)
+	var void sql.NullString
+	if err := db.QueryRow("SELECT pg_advisory_lock($1)", 7).Scan(&void); err != nil {
+		t.Errorf("error '%s' was not expected while locking", err)
+	}
+
+	go advance(10 * time.Second)
+	var status int64
+	if err := db.QueryRow("SELECT GET_LOCK(?, ?)", "jobs", 10).Scan(&status); err != nil || status != 0 {
+		t.Errorf("expected the lock to time out, but got %d and error: %v", status, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestAdvisoryLockKeyMismatch(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	ExpectAdvisoryLock(mock, 1).WillReturnError(fmt.Errorf("lock timeout"))
+
+	var void sql.NullString
+	err = db.QueryRow("SELECT pg_advisory_lock($1)", 2).Scan(&void)
+	if err == nil || err.Error() != "AdvisoryLock 'SELECT pg_advisory_lock($1)' with args [2], does not match expectation: expecting advisory lock of key 1" {
+		t.Errorf("expected an error about the mismatching key, but got: %v", err)
+	}
+}
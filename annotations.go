@@ -0,0 +1,110 @@
+package sqlmock
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AnnotationExtractor extracts annotations, like the route or the
+// controller, which a query attribution middleware attached to the
+// query. It must not call the mock.
+type AnnotationExtractor func(query string) map[string]string
+
+// AnnotatedCall is a Query() or Exec() call in the history of calls
+// kept once annotations are extracted, see ExtractAnnotations
+type AnnotatedCall struct {
+	Handle      string
+	Query       string
+	Annotations map[string]string
+}
+
+var (
+	sqlCommentRe     = regexp.MustCompile(`/\*([^*]*(?:\*[^/][^*]*)*)\*/\s*;?\s*$`)
+	sqlCommentPairRe = regexp.MustCompile(`([^=,\s]+)\s*=\s*'((?:[^'\\]|\\.)*)'`)
+)
+
+// SQLCommenter extracts annotations of the sqlcommenter format, which
+// are appended to the query as a comment of url encoded key-value pairs:
+//
+//	SELECT * FROM users /*controller='users',route='%2Fusers%2F%3Aid'*/
+func SQLCommenter(query string) map[string]string {
+	m := sqlCommentRe.FindStringSubmatch(query)
+	if m == nil {
+		return nil
+	}
+
+	var annotations map[string]string
+	for _, pair := range sqlCommentPairRe.FindAllStringSubmatch(m[1], -1) {
+		key, err := url.PathUnescape(pair[1])
+		if err != nil {
+			continue
+		}
+		value, err := url.PathUnescape(strings.Replace(pair[2], `\'`, `'`, -1))
+		if err != nil {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[key] = value
+	}
+	return annotations
+}
+
+func (c *sqlmock) ExtractAnnotations(extractors ...AnnotationExtractor) {
+	c.Lock()
+	c.extractors = append(c.extractors, extractors...)
+	c.Unlock()
+}
+
+// annotate extracts annotations of the event query, if any extractors
+// were set, and keeps the calls in history, must be called under the
+// mock lock
+func (c *sqlmock) annotate(ev *Event) {
+	if len(c.extractors) == 0 || ev.Query == "" {
+		return
+	}
+
+	for _, extract := range c.extractors {
+		for key, value := range extract(ev.Query) {
+			if ev.Annotations == nil {
+				ev.Annotations = make(map[string]string)
+			}
+			ev.Annotations[key] = value
+		}
+	}
+
+	if ev.Kind == QueryStarted || ev.Kind == ExecStarted {
+		c.annotated = append(c.annotated, AnnotatedCall{Handle: ev.Handle, Query: ev.Query, Annotations: ev.Annotations})
+	}
+}
+
+func (c *sqlmock) AnnotatedCalls() []AnnotatedCall {
+	c.Lock()
+	defer c.Unlock()
+	return append([]AnnotatedCall(nil), c.annotated...)
+}
+
+func (c *sqlmock) AllAnnotated(keys ...string) error {
+	var missing []string
+	for _, call := range c.AnnotatedCalls() {
+		var lacks []string
+		for _, key := range keys {
+			if _, ok := call.Annotations[key]; !ok {
+				lacks = append(lacks, key)
+			}
+		}
+		if len(lacks) > 0 {
+			sort.Strings(lacks)
+			missing = append(missing, fmt.Sprintf("'%s' (%s)", call.Query, strings.Join(lacks, ", ")))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("queries were not annotated: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
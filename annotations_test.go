@@ -0,0 +1,54 @@
+package sqlmock
+
+import (
+	"testing"
+)
+
+func TestSQLCommenter(t *testing.T) {
+	annotations := SQLCommenter("SELECT * FROM users /*controller='users',route='%2Fusers%2F%3Aid',note='it\\'s'*/;")
+	if len(annotations) != 3 || annotations["controller"] != "users" || annotations["route"] != "/users/:id" || annotations["note"] != "it's" {
+		t.Errorf("unexpected annotations: %v", annotations)
+	}
+
+	if annotations := SQLCommenter("SELECT /* hint */ * FROM users"); annotations != nil {
+		t.Errorf("expected no annotations of a comment within the query, but got: %v", annotations)
+	}
+}
+
+func TestExtractAnnotations(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExtractAnnotations(SQLCommenter)
+	events := mock.Subscribe(4)
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("UPDATE users SET name = ? /*route='%2Fusers',controller='users'*/", "bob"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+	if _, err := db.Exec("DELETE FROM users /*controller='cleanup'*/"); err != nil {
+		t.Errorf("error '%s' was not expected while deleting", err)
+	}
+
+	if ev := <-events; ev.Annotations["route"] != "/users" {
+		t.Errorf("expected the event to be annotated, but got: %v", ev.Annotations)
+	}
+
+	calls := mock.AnnotatedCalls()
+	if len(calls) != 2 || calls[1].Annotations["controller"] != "cleanup" {
+		t.Errorf("unexpected annotated calls: %v", calls)
+	}
+
+	if err := mock.AllAnnotated("controller"); err != nil {
+		t.Errorf("error '%s' was not expected, all calls were annotated with controller", err)
+	}
+	err = mock.AllAnnotated("controller", "route")
+	if err == nil || err.Error() != "queries were not annotated: 'DELETE FROM users /*controller='cleanup'*/' (route)" {
+		t.Errorf("expected an error about the call lacking route, but got: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+// benchMock creates a mock having a reusable expectation among
+// pending ones, which serves as a stand-in benchmark backend
+func benchMock(b *testing.B) (*sqlmock, func()) {
+	db, mock, err := New()
+	if err != nil {
+		b.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		mock.ExpectExec("DELETE FROM sessions").WillReturnResult(NewResult(0, 1))
+	}
+	mock.ExpectExec("UPDATE users SET name = \\? WHERE id = \\?").
+		WithArgs("bob", 5).
+		WillReturnResult(NewResult(0, 1)).
+		Reusable()
+	mock.ExpectQuery("SELECT id, name FROM users WHERE id = \\?").
+		WithArgs(5).
+		WillReturnRows(NewRows([]string{"id", "name"}).AddRow(5, "bob")).
+		Reusable()
+
+	return mock.(*sqlmock), func() { db.Close() }
+}
+
+func BenchmarkExec(b *testing.B) {
+	mock, done := benchMock(b)
+	defer done()
+
+	args := []driver.Value{"bob", int64(5)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mock.Exec("UPDATE users SET name = ? WHERE id = ?", args); err != nil {
+			b.Fatalf("error '%s' was not expected while executing", err)
+		}
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	mock, done := benchMock(b)
+	defer done()
+
+	args := []driver.Value{int64(5)}
+	dest := make([]driver.Value, 2)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := mock.Query("SELECT id, name FROM users WHERE id = ?", args)
+		if err != nil {
+			b.Fatalf("error '%s' was not expected while querying", err)
+		}
+		for rows.Next(dest) == nil {
+		}
+		rows.Close()
+	}
+}
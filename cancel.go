@@ -0,0 +1,50 @@
+package sqlmock
+
+// ExpectedCancel is used to manage the cancel request of a call,
+// whose context was cancelled while it was delayed.
+// Returned by *Sqlmock.ExpectCancel.
+type ExpectedCancel struct {
+	commonExpectation
+}
+
+// String returns string representation
+func (e *ExpectedCancel) String() string {
+	return "ExpectedCancel => expecting a cancel request of a call in flight (" + e.status() + ")"
+}
+
+func (c *sqlmock) ExpectCancel() *ExpectedCancel {
+	e := &ExpectedCancel{}
+	c.expect(e)
+	return e
+}
+
+// cancel handles a call to a stripped query, whose context is done
+// while the call is delayed, as a driver sends a cancel request to
+// the server. It returns the error of the call, which is cause, if
+// the cancel request was expected or tolerated.
+func (c *sqlmock) cancel(handle, query string, cause error) error {
+	matched, next, exhausted := c.matchExpectation(func(e expectation) bool {
+		_, ok := e.(*ExpectedCancel)
+		return ok
+	}, nil)
+	if next != nil {
+		return failf(handle, "cancel request of query '%s', was not expected, next expectation is: %s", query, lockedString(next))
+	}
+
+	expected, _ := matched.(*ExpectedCancel)
+	if expected == nil {
+		if c.requireExpectations {
+			msg := "cancel request of query '%s' was not expected"
+			if exhausted {
+				msg = "all expectations were already fulfilled, " + msg
+			}
+			return failf(handle, msg, query)
+		}
+		c.warnf("cancel request of query '%s' was not expected, tolerated since expectations are not required", query)
+		return cause
+	}
+
+	expected.trigger()
+	expected.Unlock()
+	return cause
+}
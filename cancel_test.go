@@ -0,0 +1,78 @@
+package sqlmock
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpectCancel(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectQuery("SELECT (.+) FROM reports").WillDelayFor(time.Minute).WillReturnRows(NewRows([]string{"id"}))
+	mock.ExpectCancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = db.QueryContext(ctx, "SELECT id FROM reports")
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected the error of the context, but got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the delay to be cut short by the context, but the query took %s", elapsed)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestUnexpectedCancel(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	mock.ExpectExec("UPDATE reports").WillDelayFor(time.Minute).WillReturnResult(NewResult(0, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = db.ExecContext(ctx, "UPDATE reports SET done = 1")
+	if err == nil {
+		t.Fatal("expected an error, since the cancel request was not expected")
+	}
+	if !strings.Contains(err.Error(), "cancel request of query 'UPDATE reports SET done = 1' was not expected") {
+		t.Errorf("expected the cancel request to be unexpected, but got: %s", err)
+	}
+}
+
+func TestExpectCancelOfCompletedCall(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE reports").WillDelayFor(time.Millisecond).WillReturnResult(NewResult(0, 1))
+	mock.ExpectCancel()
+
+	if _, err = db.ExecContext(context.Background(), "UPDATE reports SET done = 1"); err != nil {
+		t.Errorf("an error '%s' was not expected, since the call completes", err)
+	}
+	if err = mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected the cancel request to be unfulfilled, since the call completed")
+	}
+}
@@ -0,0 +1,39 @@
+package sqlmock
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var filteredStatementRe = regexp.MustCompile(`(?i)^\s*(SELECT|UPDATE|DELETE)\b`)
+
+func (c *sqlmock) RequireClause(sqlRegexStr string) {
+	re := regexp.MustCompile(sqlRegexStr)
+	c.Lock()
+	c.requiredClauses = append(c.requiredClauses, re)
+	c.Unlock()
+}
+
+// checkClauses verifies that the stripped query includes all
+// required clauses, if it is a SELECT, UPDATE or DELETE statement
+func (c *sqlmock) checkClauses(query string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.requiredClauses) == 0 {
+		return nil
+	}
+
+	m := filteredStatementRe.FindStringSubmatch(query)
+	if m == nil {
+		return nil
+	}
+
+	for _, re := range c.requiredClauses {
+		if !re.MatchString(query) {
+			return fmt.Errorf("%s query '%s' does not include required clause [%s]", strings.ToUpper(m[1]), query, re)
+		}
+	}
+	return nil
+}
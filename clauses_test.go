@@ -0,0 +1,58 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequireClause(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireClause(`(?i)\btenant_id\s*=`)
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(NewResult(1, 1))
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(NewRows([]string{"name"}))
+
+	if _, err := db.Exec("INSERT INTO users (tenant_id, name) VALUES (?, ?)", 1, "john"); err != nil {
+		t.Errorf("error '%s' was not expected, since inserts are not filtered", err)
+	}
+	if _, err := db.Exec("UPDATE users SET name = ? WHERE tenant_id = ? AND id = ?", "john", 1, 2); err != nil {
+		t.Errorf("error '%s' was not expected, since the update includes tenant_id predicate", err)
+	}
+
+	_, err = db.Query("SELECT name FROM users WHERE id = ?", 2)
+	if err == nil || !strings.Contains(err.Error(), "SELECT query 'SELECT name FROM users WHERE id = ?' does not include required clause") {
+		t.Errorf("expected an error about missing tenant_id predicate, but got: %v", err)
+	}
+}
+
+func TestRequireClauseSkipsIgnoredQueries(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireClause(`(?i)\btenant_id\s*=`)
+	mock.AllowTables("users")
+	mock.IgnoreQueries("^SELECT 1$")
+	mock.WithServerVersion(MySQL, "8.0.32")
+
+	for _, query := range []string{"SELECT 1", "SELECT VERSION()"} {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Errorf("error '%s' was not expected, since the query '%s' is ignored", err, query)
+			continue
+		}
+		rows.Close()
+	}
+	if _, err := db.Exec("SELECT 1"); err != nil {
+		t.Errorf("error '%s' was not expected, since the statement is ignored", err)
+	}
+}
@@ -0,0 +1,76 @@
+package sqlmock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a simulated clock used by sqlmock to measure
+// time dependent database behavior. It does not follow
+// the wall clock and only moves when it is advanced, so
+// that such behavior could be tested deterministically.
+type Clock struct {
+	mu      sync.Mutex
+	start   time.Time
+	now     time.Time
+	waiters []clockWaiter
+}
+
+// clockWaiter is notified once the clock reaches its time
+type clockWaiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewClock creates a simulated clock set to the given time
+func NewClock(now time.Time) *Clock {
+	return &Clock{start: now, now: now}
+}
+
+// Now returns the current simulated time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the simulated time forward by d and
+// notifies the waiters, which time has come
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.c <- c.now
+	}
+	c.waiters = pending
+}
+
+// After waits for the simulated time to be advanced by d
+// and then sends the current simulated time on the channel
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, clockWaiter{at: c.now.Add(d), c: ch})
+	return ch
+}
+
+// Waiting returns the number of waiters, which time has not come
+// yet, so that a test could advance the clock once a call blocks
+func (c *Clock) Waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
@@ -0,0 +1,46 @@
+package sqlmock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockAdvance(t *testing.T) {
+	start := time.Date(2015, 8, 27, 10, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+
+	if !clock.Now().Equal(start) {
+		t.Errorf("expected clock to be set to %s, but it is %s", start, clock.Now())
+	}
+
+	clock.Advance(time.Minute)
+	clock.Advance(time.Second)
+
+	if expected := start.Add(61 * time.Second); !clock.Now().Equal(expected) {
+		t.Errorf("expected clock to be advanced to %s, but it is %s", expected, clock.Now())
+	}
+}
+
+func TestClockAfter(t *testing.T) {
+	clock := NewClock(time.Date(2015, 8, 27, 10, 0, 0, 0, time.UTC))
+
+	soon, later := clock.After(time.Second), clock.After(time.Minute)
+	if clock.Waiting() != 2 {
+		t.Errorf("expected 2 waiters, but got %d", clock.Waiting())
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case <-soon:
+	default:
+		t.Errorf("expected the waiter to be notified once its time has come")
+	}
+	select {
+	case <-later:
+		t.Errorf("expected the waiter not to be notified before its time")
+	default:
+	}
+	if clock.Waiting() != 1 {
+		t.Errorf("expected 1 waiter, but got %d", clock.Waiting())
+	}
+}
@@ -0,0 +1,209 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Column is a mocked column definition, which allows to
+// mock column type metadata of Rows. It is created by
+// NewColumn and used with NewRowsWithColumnDefinition.
+type Column struct {
+	name      string
+	dbType    string
+	length    int64
+	precision int64
+	scale     int64
+	nullable  bool
+	goType    reflect.Type
+
+	hasLength         bool
+	hasPrecisionScale bool
+	hasNullable       bool
+}
+
+// NewColumn creates a column definition with the given name
+func NewColumn(name string) *Column {
+	return &Column{name: name}
+}
+
+// Name returns the column name
+func (c *Column) Name() string {
+	return c.name
+}
+
+// OfType sets the database system type name of the column,
+// for example "VARCHAR" or "DECIMAL".
+func (c *Column) OfType(dbType string) *Column {
+	c.dbType = dbType
+	return c
+}
+
+// WithLength sets the column type length for variable length
+// column types, such as text and binary field types, which
+// is the length in characters for unicode columns.
+func (c *Column) WithLength(length int64) *Column {
+	c.length, c.hasLength = length, true
+	return c
+}
+
+// WithPrecisionAndScale sets the precision and scale
+// for decimal column types.
+func (c *Column) WithPrecisionAndScale(precision, scale int64) *Column {
+	c.precision, c.scale, c.hasPrecisionScale = precision, scale, true
+	return c
+}
+
+// Nullable marks the column as nullable or not nullable.
+// Unless set, nullability of the column is reported unknown.
+func (c *Column) Nullable(nullable bool) *Column {
+	c.nullable, c.hasNullable = nullable, true
+	return c
+}
+
+// OfGoType declares the Go type of the column values by an
+// example value, which is one of int64, float64, bool, []byte,
+// string or time.Time. Values are converted to the declared type
+// before they are scanned, as a driver would return them, for
+// example []byte for any column read with mysql text protocol.
+// That way scan errors of a real driver, like a string scanned
+// into an int, could be reproduced. A value, which could not be
+// converted, makes Next to fail.
+func (c *Column) OfGoType(example driver.Value) *Column {
+	switch example.(type) {
+	case int64, float64, bool, []byte, string, time.Time:
+	default:
+		panic(fmt.Sprintf("Expected column '%s' Go type example to be a driver value, but got %T", c.name, example))
+	}
+	c.goType = reflect.TypeOf(example)
+	return c
+}
+
+// convert converts the value to the declared Go type of column
+func (c *Column) convert(v driver.Value) (driver.Value, error) {
+	if c.goType == nil || v == nil {
+		return v, nil
+	}
+
+	var res driver.Value
+	var err error
+	switch c.goType {
+	case reflect.TypeOf(""):
+		res = valueText(v)
+	case reflect.TypeOf([]byte(nil)):
+		res = []byte(valueText(v))
+	case reflect.TypeOf(int64(0)):
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			res = rv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			res = int64(rv.Uint())
+		default:
+			res, err = strconv.ParseInt(valueText(v), 10, 64)
+		}
+	case reflect.TypeOf(float64(0)):
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			res = float64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			res = float64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			res = rv.Float()
+		default:
+			res, err = strconv.ParseFloat(valueText(v), 64)
+		}
+	case reflect.TypeOf(false):
+		res, err = strconv.ParseBool(valueText(v))
+	case reflect.TypeOf(time.Time{}):
+		if t, ok := v.(time.Time); ok {
+			res = t
+		} else {
+			res, err = time.Parse(time.RFC3339Nano, valueText(v))
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sqlmock: cannot convert value %v of type %T in column '%s' to %s", v, v, c.name, c.goType)
+	}
+	return res, nil
+}
+
+// valueText formats the value as text
+func valueText(v driver.Value) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// NewRowsWithColumnDefinition allows Rows to be created with column
+// definitions, which type metadata is reported by database/sql
+// ColumnTypes.
+func NewRowsWithColumnDefinition(columns ...*Column) Rows {
+	cols := make([]string, len(columns))
+	for i, c := range columns {
+		cols[i] = c.name
+	}
+
+	r := NewRows(cols).(*rows)
+	r.defs = columns
+	return r
+}
+
+// column returns the definition of column at index, if defined
+func (r *rows) column(index int) *Column {
+	if index < 0 || index >= len(r.defs) {
+		return nil
+	}
+	return r.defs[index]
+}
+
+// ColumnTypeDatabaseTypeName meets http://golang.org/pkg/database/sql/driver/#RowsColumnTypeDatabaseTypeName
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	if c := r.column(index); c != nil {
+		return c.dbType
+	}
+	return ""
+}
+
+// ColumnTypeLength meets http://golang.org/pkg/database/sql/driver/#RowsColumnTypeLength
+func (r *rows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if c := r.column(index); c != nil {
+		return c.length, c.hasLength
+	}
+	return 0, false
+}
+
+// ColumnTypePrecisionScale meets http://golang.org/pkg/database/sql/driver/#RowsColumnTypePrecisionScale
+func (r *rows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if c := r.column(index); c != nil {
+		return c.precision, c.scale, c.hasPrecisionScale
+	}
+	return 0, 0, false
+}
+
+// ColumnTypeNullable meets http://golang.org/pkg/database/sql/driver/#RowsColumnTypeNullable
+func (r *rows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if c := r.column(index); c != nil {
+		return c.nullable, c.hasNullable
+	}
+	return false, false
+}
+
+// ColumnTypeScanType meets http://golang.org/pkg/database/sql/driver/#RowsColumnTypeScanType
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	if c := r.column(index); c != nil && c.goType != nil {
+		return c.goType
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
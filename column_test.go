@@ -0,0 +1,146 @@
+package sqlmock
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestColumnTypeMetadata(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rs := NewRowsWithColumnDefinition(
+		NewColumn("id").OfType("INT"),
+		NewColumn("name").OfType("NVARCHAR").WithLength(50),
+		NewColumn("price").OfType("DECIMAL").WithPrecisionAndScale(10, 2),
+	).AddRow(1, "žąsis", "9.99")
+
+	mock.ExpectQueryRow("SELECT (.+) FROM products").WillReturnRows(rs)
+
+	rows, err := db.Query("SELECT id, name, price FROM products")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying", err)
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while getting column types", err)
+	}
+
+	if len(types) != 3 {
+		t.Fatalf("expected 3 column types, but got %d", len(types))
+	}
+
+	if name := types[1].DatabaseTypeName(); name != "NVARCHAR" {
+		t.Errorf("expected name column type to be NVARCHAR, but got '%s'", name)
+	}
+
+	if _, ok := types[0].Length(); ok {
+		t.Errorf("expected id column to have no length")
+	}
+
+	if length, ok := types[1].Length(); !ok || length != 50 {
+		t.Errorf("expected name column length to be 50, but got %d (%v)", length, ok)
+	}
+
+	if precision, scale, ok := types[2].DecimalSize(); !ok || precision != 10 || scale != 2 {
+		t.Errorf("expected price column to have precision 10 and scale 2, but got %d, %d (%v)", precision, scale, ok)
+	}
+
+	if _, _, ok := types[1].DecimalSize(); ok {
+		t.Errorf("expected name column to have no decimal size")
+	}
+}
+
+func TestColumnNullableMetadata(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rs := NewRowsWithColumnDefinition(
+		NewColumn("id").Nullable(false),
+		NewColumn("email").Nullable(true),
+		NewColumn("note"),
+	).AddRow(1, nil, nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(rs)
+
+	rows, err := db.Query("SELECT id, email, note FROM users")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying", err)
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while getting column types", err)
+	}
+
+	for i, expected := range []struct{ nullable, ok bool }{{false, true}, {true, true}, {false, false}} {
+		nullable, ok := types[i].Nullable()
+		if nullable != expected.nullable || ok != expected.ok {
+			t.Errorf("expected column %s nullable to be %v (%v), but got %v (%v)", types[i].Name(), expected.nullable, expected.ok, nullable, ok)
+		}
+	}
+}
+
+func TestColumnGoType(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	columns := func() Rows {
+		return NewRowsWithColumnDefinition(
+			NewColumn("id").OfGoType([]byte(nil)),
+			NewColumn("age").OfGoType(""),
+			NewColumn("score").OfGoType(float64(0)),
+		)
+	}
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(columns().AddRow(1, "n/a", 5))
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(columns().AddRow(1, "30", "high"))
+
+	rows, err := db.Query("SELECT id, age, score FROM users")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying", err)
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while getting column types", err)
+	}
+	if st := types[0].ScanType(); st != reflect.TypeOf([]byte(nil)) {
+		t.Errorf("expected id column scan type to be []byte, but got %s", st)
+	}
+
+	var raw interface{}
+	var age int
+	var score float64
+	if !rows.Next() {
+		t.Fatalf("expected a row, but got error: %v", rows.Err())
+	}
+	err = rows.Scan(&raw, &age, &score)
+	if err == nil || !strings.Contains(err.Error(), `converting driver.Value type string ("n/a") to a int`) {
+		t.Errorf("expected string scanned into int to fail, but got: %v", err)
+	}
+	if _, ok := raw.([]byte); !ok {
+		t.Errorf("expected id to be returned as []byte, but got %T", raw)
+	}
+	rows.Close()
+
+	err = db.QueryRow("SELECT id, age, score FROM users").Scan(&raw, &age, &score)
+	if err == nil || !strings.Contains(err.Error(), "cannot convert value high of type string in column 'score' to float64") {
+		t.Errorf("expected value not convertible to float64 to fail, but got: %v", err)
+	}
+}
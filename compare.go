@@ -0,0 +1,276 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ArgMismatch describes why actual arguments of a call
+// do not match the expected ones
+type ArgMismatch struct {
+	// Index of the mismatching argument, -1 if the
+	// number of arguments is different
+	Index int
+
+	// Ordinal of the mismatching argument, as driver.NamedValue
+	// numbers it from 1, 0 if the number of arguments is different
+	Ordinal int
+
+	// Path to the mismatching value within the argument,
+	// like "[2].Name", empty for the argument itself
+	Path string
+
+	// Expected and Actual are the mismatching values
+	Expected string
+	Actual   string
+
+	// Reason tells why the values do not match
+	Reason string
+}
+
+// Error returns a description of the mismatch
+func (m *ArgMismatch) Error() string {
+	if m.Index < 0 {
+		return m.Reason
+	}
+	return fmt.Sprintf("argument %d%s %s, expected %s, but got %s", m.Index, m.Path, m.Reason, m.Expected, m.Actual)
+}
+
+// CompareArgs compares actual arguments of a call with the expected
+// ones the same way expectations do and returns the first mismatch
+// or nil, if they match. Arguments of sqlmock.Argument type are
+// matched by the argument itself.
+//
+// Numbers are compared by value, so int and int64 may match each
+// other, NaN matches NaN. Slices, maps, pointers and nested structs
+// are compared deeply, including unexported fields and cyclic values.
+// Structs given as arguments, like time.Time, are compared only by
+// type, so that any time matches.
+//
+// The comparison never panics.
+func CompareArgs(expected, actual []driver.Value) *ArgMismatch {
+	return compareArgs(expected, actual, nil)
+}
+
+// compareArgs compares arguments normalized by n
+func compareArgs(expected, actual []driver.Value, n normalizers) *ArgMismatch {
+	if len(expected) != len(actual) {
+		return &ArgMismatch{
+			Index:  -1,
+			Reason: fmt.Sprintf("expected %d arguments, but got %d", len(expected), len(actual)),
+		}
+	}
+
+	for i, v := range actual {
+		v = n.apply(v)
+		if matcher, ok := expected[i].(Argument); ok {
+			if !matcher.Match(v) {
+				return &ArgMismatch{
+					Index:    i,
+					Ordinal:  i + 1,
+					Expected: fmt.Sprintf("%+v", expected[i]),
+					Actual:   fmt.Sprintf("%+v", v),
+					Reason:   "is not matched by argument matcher",
+				}
+			}
+			continue
+		}
+
+		c := comparison{index: i}
+		if !c.values("", reflect.ValueOf(n.apply(expected[i])), reflect.ValueOf(v), true) {
+			return c.mismatch
+		}
+	}
+	return nil
+}
+
+// comparison of a single argument, which keeps
+// track of visited values to handle cycles
+type comparison struct {
+	index    int
+	visited  map[visit]bool
+	mismatch *ArgMismatch
+}
+
+// visit of comparable references, like in reflect.DeepEqual
+type visit struct {
+	expected, actual uintptr
+	typ              reflect.Type
+}
+
+func (c *comparison) fail(path string, exp, act reflect.Value, reason string) bool {
+	c.mismatch = &ArgMismatch{
+		Index:    c.index,
+		Ordinal:  c.index + 1,
+		Path:     path,
+		Expected: describeValue(exp),
+		Actual:   describeValue(act),
+		Reason:   reason,
+	}
+	return false
+}
+
+// values compares expected and actual values, top is set for
+// the argument itself, which structs are compared by type only
+func (c *comparison) values(path string, exp, act reflect.Value, top bool) bool {
+	if !exp.IsValid() || !act.IsValid() {
+		if exp.IsValid() != act.IsValid() {
+			return c.fail(path, exp, act, "differs")
+		}
+		return true
+	}
+
+	switch exp.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch act.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if exp.Int() == act.Int() {
+				return true
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if exp.Int() >= 0 && uint64(exp.Int()) == act.Uint() {
+				return true
+			}
+		default:
+			return c.fail(path, exp, act, "differs by type")
+		}
+		return c.fail(path, exp, act, "differs")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch act.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if exp.Uint() == act.Uint() {
+				return true
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if act.Int() >= 0 && exp.Uint() == uint64(act.Int()) {
+				return true
+			}
+		default:
+			return c.fail(path, exp, act, "differs by type")
+		}
+		return c.fail(path, exp, act, "differs")
+	case reflect.Float32, reflect.Float64:
+		switch act.Kind() {
+		case reflect.Float32, reflect.Float64:
+			e, a := exp.Float(), act.Float()
+			if e == a || math.IsNaN(e) && math.IsNaN(a) {
+				return true
+			}
+			return c.fail(path, exp, act, "differs")
+		}
+		return c.fail(path, exp, act, "differs by type")
+	case reflect.Complex64, reflect.Complex128:
+		switch act.Kind() {
+		case reflect.Complex64, reflect.Complex128:
+			if exp.Complex() == act.Complex() {
+				return true
+			}
+			return c.fail(path, exp, act, "differs")
+		}
+		return c.fail(path, exp, act, "differs by type")
+	case reflect.String:
+		if act.Kind() != reflect.String {
+			return c.fail(path, exp, act, "differs by type")
+		}
+		if exp.String() != act.String() {
+			return c.fail(path, exp, act, "differs")
+		}
+		return true
+	case reflect.Bool:
+		if act.Kind() != reflect.Bool {
+			return c.fail(path, exp, act, "differs by type")
+		}
+		if exp.Bool() != act.Bool() {
+			return c.fail(path, exp, act, "differs")
+		}
+		return true
+	}
+
+	if top && exp.Kind() == reflect.Struct {
+		// compare types like time.Time based on type only
+		if exp.Type() != act.Type() {
+			return c.fail(path, exp, act, "differs by type")
+		}
+		return true
+	}
+
+	if exp.Type() != act.Type() {
+		return c.fail(path, exp, act, "differs by type")
+	}
+
+	switch exp.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if exp.IsNil() || act.IsNil() {
+			if exp.IsNil() != act.IsNil() {
+				return c.fail(path, exp, act, "differs")
+			}
+			return true
+		}
+		if c.seen(exp, act) {
+			return true
+		}
+	}
+
+	switch exp.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return c.values(path, exp.Elem(), act.Elem(), false)
+	case reflect.Slice, reflect.Array:
+		if exp.Len() != act.Len() {
+			return c.fail(path, exp, act, fmt.Sprintf("differs by length %d and %d", exp.Len(), act.Len()))
+		}
+		for i := 0; i < exp.Len(); i++ {
+			if !c.values(fmt.Sprintf("%s[%d]", path, i), exp.Index(i), act.Index(i), false) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if exp.Len() != act.Len() {
+			return c.fail(path, exp, act, fmt.Sprintf("differs by length %d and %d", exp.Len(), act.Len()))
+		}
+		for _, key := range exp.MapKeys() {
+			at := fmt.Sprintf("%s[%s]", path, describeValue(key))
+			if !c.values(at, exp.MapIndex(key), act.MapIndex(key), false) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < exp.NumField(); i++ {
+			if !c.values(path+"."+exp.Type().Field(i).Name, exp.Field(i), act.Field(i), false) {
+				return false
+			}
+		}
+		return true
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if exp.Pointer() != act.Pointer() {
+			return c.fail(path, exp, act, "differs")
+		}
+		return true
+	}
+	return true
+}
+
+// seen records the pair of references being compared and
+// tells whether it was already visited, which is a cycle
+func (c *comparison) seen(exp, act reflect.Value) bool {
+	v := visit{exp.Pointer(), act.Pointer(), exp.Type()}
+	if c.visited == nil {
+		c.visited = make(map[visit]bool)
+	}
+	if c.visited[v] {
+		return true
+	}
+	c.visited[v] = true
+	return false
+}
+
+// describeValue formats a value, which may be an unexported field
+func describeValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return fmt.Sprintf("%+v (%s)", v, v.Type())
+}
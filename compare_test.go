@@ -0,0 +1,73 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+type node struct {
+	name string
+	next *node
+}
+
+func TestCompareArgs(t *testing.T) {
+	cyclic := func(name string) *node {
+		n := &node{name: name}
+		n.next = n
+		return n
+	}
+
+	cases := []struct {
+		expected, actual []driver.Value
+		mismatch         string
+	}{
+		{[]driver.Value{5, "str"}, []driver.Value{int64(5), "str"}, ""},
+		{[]driver.Value{5.5}, []driver.Value{5}, "argument 0 differs by type"},
+		{[]driver.Value{uint(5)}, []driver.Value{int64(5)}, ""},
+		{[]driver.Value{-1}, []driver.Value{uint64(math.MaxUint64)}, "argument 0 differs"},
+		{[]driver.Value{math.NaN()}, []driver.Value{math.NaN()}, ""},
+		{[]driver.Value{true}, []driver.Value{false}, "argument 0 differs"},
+		{[]driver.Value{time.Now()}, []driver.Value{time.Time{}}, ""},
+		{[]driver.Value{time.Now()}, []driver.Value{node{name: "a"}}, "argument 0 differs by type"},
+		{[]driver.Value{[]byte("abc")}, []driver.Value{[]byte("abd")}, "argument 0[2] differs"},
+		{[]driver.Value{&node{name: "a"}}, []driver.Value{&node{name: "b"}}, "argument 0.name differs"},
+		{[]driver.Value{cyclic("a")}, []driver.Value{cyclic("a")}, ""},
+		{[]driver.Value{cyclic("a")}, []driver.Value{cyclic("b")}, "argument 0.name differs"},
+		{[]driver.Value{map[string]int{"a": 1}}, []driver.Value{map[string]int{"b": 1}}, "argument 0[a (string)] differs"},
+		{[]driver.Value{nil}, []driver.Value{1}, "argument 0 differs"},
+		{[]driver.Value{1, 2}, []driver.Value{1}, "expected 2 arguments, but got 1"},
+		{[]driver.Value{matcher{}}, []driver.Value{"anything"}, ""},
+	}
+
+	for i, c := range cases {
+		mismatch := CompareArgs(c.expected, c.actual)
+		switch {
+		case c.mismatch == "" && mismatch != nil:
+			t.Errorf("case %d: arguments should match, but got: %s", i, mismatch)
+		case c.mismatch != "" && mismatch == nil:
+			t.Errorf("case %d: arguments should not match", i)
+		case c.mismatch != "" && !strings.HasPrefix(mismatch.Error(), c.mismatch):
+			t.Errorf("case %d: expected mismatch '%s', but got: %s", i, c.mismatch, mismatch)
+		}
+	}
+}
+
+func TestArgumentTypeMismatchIsReported(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectExec("UPDATE sales").WithArgs(5.5).WillReturnResult(NewResult(0, 1))
+
+	_, err = db.Exec("UPDATE sales SET x = ?", 5)
+	if err == nil || !strings.Contains(err.Error(), "argument 0 differs by type, expected 5.5 (float64), but got 5 (int64)") {
+		t.Errorf("expected an error describing the argument mismatch, but got: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// conformanceCase exercises the mock through database/sql the way
+// its own driver tests exercise a driver, so that Rows, Tx and Stmt
+// lifecycles of the mock stay faithful to real drivers
+type conformanceCase struct {
+	name string
+	run  func(t *testing.T, db *sql.DB, mock Sqlmock)
+}
+
+var conformanceSuite = []conformanceCase{
+	{"rows hold the connection until closed", func(t *testing.T, db *sql.DB, mock Sqlmock) {
+		mock.ExpectQuery("SELECT id FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 2))
+
+		rs, err := db.Query("SELECT id FROM users")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := db.Exec("DELETE FROM users")
+			done <- err
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("exec should wait for the connection, held by open rows")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		if err := rs.Close(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("exec should get the connection, released by closed rows")
+		}
+	}},
+	{"rows release the connection once read", func(t *testing.T, db *sql.DB, mock Sqlmock) {
+		mock.ExpectQuery("SELECT id FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+
+		rs, err := db.Query("SELECT id FROM users")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		for rs.Next() {
+		}
+		if _, err := db.Exec("DELETE FROM users"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}},
+	{"query row without rows returns ErrNoRows", func(t *testing.T, db *sql.DB, mock Sqlmock) {
+		mock.ExpectQuery("SELECT id FROM users").WillReturnRows(NewRows([]string{"id"}))
+
+		var id int
+		if err := db.QueryRow("SELECT id FROM users").Scan(&id); err != sql.ErrNoRows {
+			t.Fatalf("expected sql.ErrNoRows, but got: %v", err)
+		}
+	}},
+	{"rows error is returned by Err", func(t *testing.T, db *sql.DB, mock Sqlmock) {
+		rowErr := fmt.Errorf("row error")
+		mock.ExpectQuery("SELECT id FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, rowErr))
+
+		rs, err := db.Query("SELECT id FROM users")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		defer rs.Close()
+
+		var n int
+		for rs.Next() {
+			n++
+		}
+		if n != 1 || rs.Err() != rowErr {
+			t.Fatalf("expected one row and the row error, but got %d rows and: %v", n, rs.Err())
+		}
+	}},
+	{"scan converts driver values", func(t *testing.T, db *sql.DB, mock Sqlmock) {
+		mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"id", "count"}).AddRow(int64(7), []byte("42")))
+
+		var id string
+		var count int
+		if err := db.QueryRow("SELECT").Scan(&id, &count); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if id != "7" || count != 42 {
+			t.Fatalf("expected converted values 7 and 42, but got %s and %d", id, count)
+		}
+	}},
+	{"transaction is done after commit", func(t *testing.T, db *sql.DB, mock Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := tx.Commit(); err != sql.ErrTxDone {
+			t.Fatalf("expected sql.ErrTxDone, but got: %v", err)
+		}
+		if _, err := tx.Exec("DELETE FROM users"); err != sql.ErrTxDone {
+			t.Fatalf("expected sql.ErrTxDone, but got: %v", err)
+		}
+	}},
+	{"transaction statement is closed with transaction", func(t *testing.T, db *sql.DB, mock Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectPrepare("DELETE FROM users")
+		mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+		mock.ExpectRollback()
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		stmt, err := tx.Prepare("DELETE FROM users WHERE id = ?")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := stmt.Exec(1); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := stmt.Exec(2); err == nil {
+			t.Fatal("expected an error, since the statement was closed with its transaction")
+		}
+	}},
+	{"rows close error is returned by Close", func(t *testing.T, db *sql.DB, mock Sqlmock) {
+		closeErr := fmt.Errorf("close error")
+		mock.ExpectQuery("SELECT id FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1).CloseError(closeErr))
+
+		rs, err := db.Query("SELECT id FROM users")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := rs.Close(); err != closeErr {
+			t.Fatalf("expected the close error, but got: %v", err)
+		}
+	}},
+}
+
+func TestConformance(t *testing.T) {
+	for _, c := range conformanceSuite {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			db, mock, err := New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			db.SetMaxOpenConns(1)
+			mock.RequireExpectations(true)
+			c.run(t, db, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expections: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package sqlmock
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errNamedArgs is returned for named arguments, which the mock does not
+// support, the same as database/sql does for drivers without them
+var errNamedArgs = errors.New("sql: driver does not support the use of Named Parameters")
+
+// values converts named values of a call to a stripped query to the
+// positional arguments, which expectations are matched against. The
+// ordinals of values must be continuous from 1, as database/sql numbers
+// them, so that mis-numbered values built by hand are caught.
+func values(handle, query string, named []driver.NamedValue) ([]driver.Value, error) {
+	args := make([]driver.Value, len(named))
+	for i, nv := range named {
+		if nv.Name != "" {
+			return nil, errNamedArgs
+		}
+		if nv.Ordinal != i+1 {
+			mismatch := &ArgMismatch{
+				Index:    i,
+				Ordinal:  nv.Ordinal,
+				Expected: fmt.Sprintf("ordinal %d", i+1),
+				Actual:   fmt.Sprintf("ordinal %d", nv.Ordinal),
+				Reason:   "is out of sequence",
+			}
+			return nil, failf(handle, "query '%s', args are not numbered continuously from 1: %s", query, mismatch)
+		}
+		args[i] = nv.Value
+	}
+	return args, nil
+}
+
+// sleep delays a call for the duration, unless its context is done
+// earlier, then the error of the context is returned
+func sleep(ctx context.Context, d time.Duration) error {
+	if ctx.Done() == nil {
+		time.Sleep(d)
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BeginTx meets http://golang.org/pkg/database/sql/driver/#ConnBeginTx
+func (c *sqlmock) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.begin("", opts)
+}
+
+// ExecContext meets http://golang.org/pkg/database/sql/driver/#ExecerContext
+func (c *sqlmock) ExecContext(ctx context.Context, query string, named []driver.NamedValue) (driver.Result, error) {
+	args, err := values("", query, named)
+	if err != nil {
+		return nil, err
+	}
+	return c.exec(ctx, "", query, args)
+}
+
+// PrepareContext meets http://golang.org/pkg/database/sql/driver/#ConnPrepareContext
+func (c *sqlmock) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.prepare("", query)
+}
+
+// QueryContext meets http://golang.org/pkg/database/sql/driver/#QueryerContext
+func (c *sqlmock) QueryContext(ctx context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args, err := values("", query, named)
+	if err != nil {
+		return nil, err
+	}
+	return c.query(ctx, "", query, args)
+}
+
+func (h *handle) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return h.begin(h.tag, opts)
+}
+
+func (h *handle) ExecContext(ctx context.Context, query string, named []driver.NamedValue) (driver.Result, error) {
+	args, err := values(h.tag, query, named)
+	if err != nil {
+		return nil, err
+	}
+	return h.exec(ctx, h.tag, query, args)
+}
+
+func (h *handle) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return h.prepare(h.tag, query)
+}
+
+func (h *handle) QueryContext(ctx context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args, err := values(h.tag, query, named)
+	if err != nil {
+		return nil, err
+	}
+	return h.query(ctx, h.tag, query, args)
+}
+
+func (stmt *statement) ExecContext(ctx context.Context, named []driver.NamedValue) (driver.Result, error) {
+	args, err := values(stmt.handle, stmt.query, named)
+	if err != nil {
+		return nil, err
+	}
+	stmt.executed(args)
+	return stmt.conn.exec(ctx, stmt.handle, stmt.query, args)
+}
+
+func (stmt *statement) QueryContext(ctx context.Context, named []driver.NamedValue) (driver.Rows, error) {
+	args, err := values(stmt.handle, stmt.query, named)
+	if err != nil {
+		return nil, err
+	}
+	stmt.executed(args)
+	return stmt.conn.query(ctx, stmt.handle, stmt.query, args)
+}
+
+// WithContextCheck verifies the context the query is made with, for
+// example that it has a deadline or carries a tenant, the matched call
+// fails with the error of the check. Calls made without a context are
+// checked with context.Background(). The check must not call the mock.
+func (e *ExpectedQuery) WithContextCheck(check func(ctx context.Context) error) *ExpectedQuery {
+	e.ctxCheck = check
+	return e
+}
+
+// WithContextCheck verifies the context the statement is made with,
+// see ExpectedQuery.WithContextCheck
+func (e *ExpectedExec) WithContextCheck(check func(ctx context.Context) error) *ExpectedExec {
+	e.ctxCheck = check
+	return e
+}
+
+// checkContext runs the context check of the expectation, if any
+func (e *queryBasedExpectation) checkContext(ctx context.Context) error {
+	if e.ctxCheck == nil {
+		return nil
+	}
+	return e.ctxCheck(ctx)
+}
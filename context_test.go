@@ -0,0 +1,121 @@
+package sqlmock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBeginTxReadOnly(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	_, err = tx.ExecContext(context.Background(), "DELETE FROM users WHERE id = ?", 1)
+	if err == nil || err.Error() != "ERROR: cannot execute DELETE in a read-only transaction" {
+		t.Errorf("expected the read-only transaction to reject DELETE, but got: %v", err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Errorf("an error '%s' was not expected when rolling back a transaction", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestNamedArgsAreRejected(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WithArgs("bob").WillReturnResult(NewResult(0, 1))
+
+	_, err = db.ExecContext(context.Background(), "UPDATE users SET name = :name", sql.Named("name", "bob"))
+	if err != errNamedArgs {
+		t.Errorf("expected named arguments to be rejected, but got: %v", err)
+	}
+	if _, err = db.ExecContext(context.Background(), "UPDATE users SET name = ?", "bob"); err != nil {
+		t.Errorf("an error '%s' was not expected, since the arguments match", err)
+	}
+}
+
+type tenantKey struct{}
+
+func TestWithContextCheck(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	hasDeadline := func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			return errors.New("context has no deadline")
+		}
+		return nil
+	}
+	ofTenant := func(ctx context.Context) error {
+		if tenant, _ := ctx.Value(tenantKey{}).(string); tenant != "acme" {
+			return fmt.Errorf("context is of tenant %q", tenant)
+		}
+		return nil
+	}
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectQuery("SELECT (.+) FROM users").WithContextCheck(hasDeadline).WillReturnRows(NewRows([]string{"id"}))
+	mock.ExpectExec("DELETE FROM users").WithContextCheck(ofTenant).WillReturnResult(NewResult(0, 1))
+
+	_, err = db.QueryContext(context.Background(), "SELECT id FROM users")
+	if err == nil || err.Error() != "query 'SELECT id FROM users' was made with an unexpected context: context has no deadline" {
+		t.Errorf("expected the query to fail the context check, but got: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	if _, err = db.ExecContext(ctx, "DELETE FROM users WHERE id = ?", 1); err != nil {
+		t.Errorf("an error '%s' was not expected, since the context passes the check", err)
+	}
+}
+
+func TestNamedValueOrdinals(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO users").WithArgs("bob", "alice").WillReturnResult(NewResult(0, 2))
+
+	conn := mock.(*sqlmock)
+	named := []driver.NamedValue{{Ordinal: 1, Value: "bob"}, {Ordinal: 3, Value: "alice"}}
+	_, err = conn.ExecContext(context.Background(), "INSERT INTO users(name) VALUES ($1), ($2)", named)
+	if err == nil || err.Error() != "query 'INSERT INTO users(name) VALUES ($1), ($2)', args are not numbered continuously from 1: argument 1 is out of sequence, expected ordinal 2, but got ordinal 3" {
+		t.Errorf("expected the mis-numbered argument to be reported, but got: %v", err)
+	}
+
+	named[1].Ordinal = 2
+	if _, err = conn.ExecContext(context.Background(), "INSERT INTO users(name) VALUES ($1), ($2)", named); err != nil {
+		t.Errorf("an error '%s' was not expected, since the arguments are numbered continuously", err)
+	}
+
+	if mismatch := CompareArgs([]driver.Value{"bob", "alice"}, []driver.Value{"bob", "eve"}); mismatch == nil || mismatch.Ordinal != 2 {
+		t.Errorf("expected the mismatch to be of the argument with ordinal 2, but got: %+v", mismatch)
+	}
+}
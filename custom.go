@@ -0,0 +1,158 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sync"
+)
+
+// CustomExpectation is an expectation of a driver specific statement,
+// like LISTEN or NOTIFY of postgres, which is reached through Exec()
+// or Query() and is implemented outside of sqlmock. It is queued by
+// ExpectCustom and takes part in ordering and verification the same
+// way as the expectations of sqlmock do.
+type CustomExpectation interface {
+	// Match tells whether the stripped query and its arguments
+	// are handled by this expectation
+	Match(query string, args []driver.Value) bool
+
+	// Handle returns the result of the matched call, the result
+	// is used for Exec() and the rows for Query() calls. It may
+	// block, like a statement waiting for a lock would.
+	Handle(query string, args []driver.Value) (driver.Result, driver.Rows, error)
+
+	// String describes the expectation in errors
+	String() string
+}
+
+// CustomVerifier may be implemented by a CustomExpectation, which
+// verifies how it was used, once ExpectationsWereMet is called
+type CustomVerifier interface {
+	Verify() error
+}
+
+// expectationKind recognizes statements of a custom kind
+type expectationKind struct {
+	name       string
+	recognizes func(query string) bool
+}
+
+var (
+	kindsMu sync.RWMutex
+	kinds   []expectationKind
+)
+
+// RegisterExpectationKind registers a kind of driver specific statements,
+// which are recognized by their stripped query. Once a custom expectation
+// of that kind is queued by ExpectCustom, Exec() and Query() calls of a
+// recognized statement are matched only to custom expectations of that
+// kind, instead of ExpectExec or ExpectQuery ones. Mocks, which queued
+// none, match them as any other statement. Kinds registered earlier take
+// precedence.
+func RegisterExpectationKind(name string, recognizes func(query string) bool) {
+	kindsMu.Lock()
+	defer kindsMu.Unlock()
+
+	for i, k := range kinds {
+		if k.name == name {
+			kinds[i].recognizes = recognizes
+			return
+		}
+	}
+	kinds = append(kinds, expectationKind{name: name, recognizes: recognizes})
+}
+
+// recognizedKind returns the name of the kind, which recognizes
+// the stripped query, or an empty string
+func recognizedKind(query string) string {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+
+	for _, k := range kinds {
+		if k.recognizes(query) {
+			return k.name
+		}
+	}
+	return ""
+}
+
+// customKind returns the kind of custom expectations, which handle
+// the stripped query, or an empty string if the query is recognized
+// by no kind, or no custom expectation of its kind was queued
+func (c *sqlmock) customKind(query string) string {
+	kind := recognizedKind(query)
+	if kind == "" {
+		return ""
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	for _, e := range c.expected {
+		if ce, ok := e.(*customExpectation); ok && ce.kind == kind {
+			return kind
+		}
+	}
+	return ""
+}
+
+// customExpectation queues a CustomExpectation of the kind
+type customExpectation struct {
+	commonExpectation
+	kind   string
+	custom CustomExpectation
+}
+
+func (e *customExpectation) String() string {
+	return fmt.Sprintf("%s => %s (%s)", e.kind, e.custom, e.status())
+}
+
+func (e *customExpectation) verify() error {
+	e.Lock()
+	triggered := e.triggered
+	e.Unlock()
+
+	if v, ok := e.custom.(CustomVerifier); ok && triggered {
+		return v.Verify()
+	}
+	return nil
+}
+
+func (c *sqlmock) ExpectCustom(kind string, e CustomExpectation) {
+	c.expect(&customExpectation{kind: kind, custom: e})
+}
+
+// handleCustom matches a call of the custom kind to its expectations
+func (c *sqlmock) handleCustom(handle, kind, query string, args []driver.Value) (driver.Result, driver.Rows, error) {
+	matched, next, exhausted := c.matchExpectation(func(e expectation) bool {
+		ce, ok := e.(*customExpectation)
+		return ok && ce.kind == kind
+	}, func(e expectation) bool {
+		return e.(*customExpectation).custom.Match(query, args)
+	})
+	if next != nil {
+		return nil, nil, failf(handle, "call to %s '%s' with args %+v, was not expected, next expectation is: %s", kind, query, c.redact.args(args), lockedString(next))
+	}
+
+	expected, _ := matched.(*customExpectation)
+	if expected == nil {
+		if c.requireExpectations {
+			msg := "call to %s '%s' with args %+v was not expected"
+			if exhausted {
+				msg = "all expectations were already fulfilled, " + msg
+			}
+			return nil, nil, failf(handle, msg, kind, query, c.redact.args(args))
+		}
+		c.warnf("call to %s '%s' with args %+v was not expected, tolerated since expectations are not required", kind, query, c.redact.args(args))
+		return NewResult(0, 0), NewRows(nil).(*rows).cursor(), nil // database/sql requires both
+	}
+
+	expected.trigger()
+	matches := expected.custom.Match(query, args)
+	expected.Unlock()
+
+	if !matches {
+		return nil, nil, failf(handle, "%s '%s' with args %+v, does not match expectation: %s", kind, query, c.redact.args(args), expected.custom)
+	}
+	// handled without the lock, since handling may block
+	return expected.custom.Handle(query, args)
+}
@@ -0,0 +1,95 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+// expectedWatch is an expectation of a WATCH statement of a custom protocol
+type expectedWatch struct {
+	channel string
+}
+
+func (e *expectedWatch) Match(query string, args []driver.Value) bool {
+	return query == "WATCH "+e.channel
+}
+
+func (e *expectedWatch) Handle(query string, args []driver.Value) (driver.Result, driver.Rows, error) {
+	return driver.ResultNoRows, nil, nil
+}
+
+func (e *expectedWatch) String() string {
+	return fmt.Sprintf("expecting WATCH on channel %s", e.channel)
+}
+
+func init() {
+	RegisterExpectationKind("Watch", func(query string) bool {
+		return hasPrefixFold(query, "WATCH ")
+	})
+}
+
+func TestCustomExpectationKind(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectBegin()
+	mock.ExpectCustom("Watch", &expectedWatch{channel: "orders"})
+	mock.ExpectCommit()
+	mock.ExpectCustom("Watch", &expectedWatch{channel: "users"})
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err := tx.Exec("WATCH orders"); err != nil {
+		t.Errorf("error '%s' was not expected while watching", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while committing", err)
+	}
+
+	_, err = db.Exec("WATCH payments")
+	if err == nil || err.Error() != "Watch 'WATCH payments' with args [], does not match expectation: expecting WATCH on channel users" {
+		t.Errorf("expected an error about the mismatching custom expectation, but got: %v", err)
+	}
+}
+
+func TestToleratedCustomExpectationKind(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectCustom("Watch", &expectedWatch{channel: "orders"})
+
+	if _, err := db.Exec("WATCH orders"); err != nil {
+		t.Errorf("error '%s' was not expected while watching", err)
+	}
+
+	rows, err := db.Query("WATCH users")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected, since the call is tolerated", err)
+	}
+	if rows.Next() {
+		t.Error("expected no rows for a tolerated call")
+	}
+	if err := rows.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing rows", err)
+	}
+
+	res, err := db.Exec("WATCH users")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected, since the call is tolerated", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected != 0 {
+		t.Errorf("expected no rows to be affected by a tolerated call, but got: %d, %v", affected, err)
+	}
+}
@@ -0,0 +1,112 @@
+package sqlmock
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+func (c *sqlmock) MergeDuplicates() (int, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	var problems []string
+	var merged int
+	var kept []expectation
+	var positions []int          // of kept expectations, as they were queued
+	keys := make(map[string]int) // the first of duplicates in kept
+	for i, e := range c.expected {
+		e.Lock()
+		key, ok := duplicateKey(e)
+		e.Unlock()
+
+		first, seen := keys[key]
+		if c.ordered && seen && first != len(kept)-1 {
+			seen = false // only consecutive duplicates are merged in order
+		}
+		if !ok || !seen {
+			if ok {
+				keys[key] = len(kept)
+			}
+			kept, positions = append(kept, e), append(positions, i)
+			continue
+		}
+
+		into := kept[first]
+		into.Lock()
+		e.Lock()
+		same := sameOutcome(into, e)
+		if same {
+			a, b := into.(Expected).common(), e.(Expected).common()
+			a.times = timesOf(a) + timesOf(b)
+		}
+		e.Unlock()
+		into.Unlock()
+
+		if !same {
+			problems = append(problems, fmt.Sprintf("expectation %d %T matches the same as expectation %d, but differs otherwise", i+1, e, positions[first]+1))
+			kept, positions = append(kept, e), append(positions, i)
+			continue
+		}
+		merged++
+	}
+	c.expected = kept
+
+	if len(problems) > 0 {
+		return merged, fmt.Errorf("expectations conflict:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return merged, nil
+}
+
+// duplicateKey identifies the Query() and Exec() calls the locked
+// expectation matches, by its stripped sql regexp and arguments.
+// Only expectations, which were not called yet, are merged.
+func duplicateKey(e expectation) (string, bool) {
+	var kind string
+	var q *queryBasedExpectation
+	switch t := e.(type) {
+	case *ExpectedExec:
+		kind, q = "exec", &t.queryBasedExpectation
+	case *ExpectedQuery:
+		kind, q = "query", &t.queryBasedExpectation
+	default:
+		return "", false
+	}
+	if q.calls > 0 || q.reuse || q.group != nil || len(q.windows) > 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%s %s %#v %s %d", kind, stripQuery(q.sqlRegex.String()), q.args, q.onHandle, q.stepIndex), true
+}
+
+// sameOutcome tells whether the locked duplicates behave the same,
+// apart from the number of times they are expected to be called
+func sameOutcome(a, b expectation) bool {
+	switch x := a.(type) {
+	case *ExpectedExec:
+		y := b.(*ExpectedExec)
+		return sameCall(&x.queryBasedExpectation, &y.queryBasedExpectation) &&
+			x.deadlock == nil && y.deadlock == nil &&
+			reflect.DeepEqual(x.result, y.result)
+	case *ExpectedQuery:
+		y := b.(*ExpectedQuery)
+		return sameCall(&x.queryBasedExpectation, &y.queryBasedExpectation) &&
+			x.perCall == y.perCall && x.queryRow == y.queryRow &&
+			reflect.DeepEqual(x.sets, y.sets) && reflect.DeepEqual(x.stale, y.stale)
+	}
+	return false
+}
+
+func sameCall(x, y *queryBasedExpectation) bool {
+	return reflect.DeepEqual(x.err, y.err) && x.priority == y.priority &&
+		x.delay == y.delay && x.latency == y.latency &&
+		x.timesOut == y.timesOut && x.timeout == y.timeout &&
+		x.ctxCheck == nil && y.ctxCheck == nil
+}
+
+// timesOf returns how many times the expectation is expected to be called
+func timesOf(e *commonExpectation) int {
+	if e.times < 1 {
+		return 1
+	}
+	return e.times
+}
@@ -0,0 +1,38 @@
+package sqlmock
+
+import (
+	"testing"
+)
+
+func TestMergeDuplicates(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	mock.ExpectExec("UPDATE users").WithArgs(1).WillReturnResult(NewResult(0, 1))
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(NewRows([]string{"name"}).AddRow("bob"))
+	mock.ExpectExec("UPDATE  users").WithArgs(1).WillReturnResult(NewResult(0, 1)).Times(2)
+	mock.ExpectExec("UPDATE users").WithArgs(2).WillReturnResult(NewResult(0, 1))
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(NewRows([]string{"name"}).AddRow("alice"))
+
+	merged, err := mock.MergeDuplicates()
+	if merged != 1 {
+		t.Errorf("expected 1 expectation to be merged, but got %d", merged)
+	}
+	if err == nil || err.Error() != "expectations conflict:\n  - expectation 5 *sqlmock.ExpectedQuery matches the same as expectation 2, but differs otherwise" {
+		t.Errorf("expected an error about the conflicting queries, but got: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := db.Exec("UPDATE users SET n = n + 1 WHERE id = ?", 1); err != nil {
+			t.Errorf("an error '%s' was not expected, since the merged expectation is expected 3 times", err)
+		}
+	}
+	if _, err := db.Exec("UPDATE users SET n = n + 1 WHERE id = ?", 1); err == nil {
+		t.Errorf("expected an error, since the merged expectation was called 3 times")
+	}
+}
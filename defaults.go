@@ -0,0 +1,89 @@
+package sqlmock
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ExpectQueriesOf pre-registers a lenient expectation for every exported
+// string field of queries, which is a struct, or a pointer to a struct,
+// holding the queries of a repository:
+//
+//	var queries = struct {
+//		UserByID   string
+//		DeleteUser string
+//	}{
+//		UserByID:   "SELECT id, name FROM users WHERE id = ?",
+//		DeleteUser: "DELETE FROM users WHERE id = ?",
+//	}
+//
+// The expectations match the query exactly, with any arguments, any
+// number of times, and are reusable, so that they are matched only
+// when no other expectation matches. So a test overrides only the
+// queries it is interested in. Queries return no rows and execs
+// return an empty result by default.
+func ExpectQueriesOf(mock SqlmockCommon, queries interface{}) error {
+	v := reflect.ValueOf(queries)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("expected a struct of queries, but got %T", queries)
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" || field.Type.Kind() != reflect.String {
+			continue // unexported or not a query
+		}
+		if err := expectDefault(mock, field.Name, v.Field(i).String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpectQueryFiles pre-registers a lenient expectation, the same way as
+// ExpectQueriesOf does, for every file matching the pattern, like
+// "queries/*.sql", which holds a single query. So that the files,
+// embedded by the application, are expected from the source directory.
+func ExpectQueryFiles(mock SqlmockCommon, pattern string) error {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid query files pattern '%s': %s", pattern, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no query files match pattern '%s'", pattern)
+	}
+
+	for _, file := range files {
+		query, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read query file: %s", err)
+		}
+		if err = expectDefault(mock, filepath.Base(file), string(query)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expectDefault queues a reusable expectation of the named query
+func expectDefault(mock SqlmockCommon, name, query string) error {
+	query = strings.TrimRight(stripQuery(query), "; ")
+	if query == "" {
+		return fmt.Errorf("query '%s' is empty", name)
+	}
+
+	sqlRegexStr := "^" + regexp.QuoteMeta(query) + ";?$"
+	if returnsRows(strings.ToUpper(query)) {
+		mock.ExpectQuery(sqlRegexStr).WillReturnRows(NewRows(nil)).Reusable()
+	} else {
+		mock.ExpectExec(sqlRegexStr).WillReturnResult(NewResult(0, 0)).Reusable()
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpectQueriesOf(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	queries := struct {
+		UserByID   string
+		DeleteUser string
+		timeout    int
+	}{
+		UserByID: `SELECT id, name
+			FROM users WHERE id = ?`,
+		DeleteUser: "DELETE FROM users WHERE id = ?",
+	}
+	if err := ExpectQueriesOf(mock, &queries); err != nil {
+		t.Fatalf("error '%s' was not expected while expecting queries", err)
+	}
+	mock.ExpectQuery("SELECT id, name FROM users").WithArgs(5).WillReturnRows(NewRows([]string{"id", "name"}).AddRow(5, "john"))
+
+	var id int
+	var name string
+	if err := db.QueryRow(queries.UserByID, 5).Scan(&id, &name); err != nil || name != "john" {
+		t.Errorf("expected the overridden query to return john, but got %q and error: %v", name, err)
+	}
+	if err := db.QueryRow(queries.UserByID, 6).Scan(&id, &name); err != sql.ErrNoRows {
+		t.Errorf("expected the default query to return no rows, but got: %v", err)
+	}
+	if _, err := db.Exec(queries.DeleteUser, 6); err != nil {
+		t.Errorf("error '%s' was not expected while deleting a user", err)
+	}
+
+	if err := ExpectQueriesOf(mock, "SELECT 1"); err == nil {
+		t.Error("expected an error, since queries are not a struct")
+	}
+}
+
+func TestExpectQueryFiles(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "sqlmock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "users.sql"), []byte("SELECT id, name FROM users;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	if err := ExpectQueryFiles(mock, filepath.Join(dir, "*.sql")); err != nil {
+		t.Fatalf("error '%s' was not expected while expecting query files", err)
+	}
+
+	rs, err := db.Query("SELECT id, name FROM users;")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while selecting users", err)
+	}
+	rs.Close()
+
+	if err := ExpectQueryFiles(mock, filepath.Join(dir, "*.missing")); err == nil {
+		t.Error("expected an error, since no files match the pattern")
+	}
+}
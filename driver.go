@@ -4,21 +4,30 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	DefaultMatchExpectationsInOrder = false
-	DefaultRequireExpectations = false
+	DefaultRequireExpectations      = false
+	DefaultMatchPolicy              = MatchFirst
 )
 
 var pool *mockDriver
 var defaultOrdered = DefaultMatchExpectationsInOrder
 var defaultRequire = DefaultRequireExpectations
+var defaultPolicy = DefaultMatchPolicy
+var defaultStrictWarnings bool
+var defaultMiddleware []RowsMiddleware
 
 func init() {
 	pool = &mockDriver{
-		conns: make(map[string]*sqlmock),
+		conns:   make(map[string]*sqlmock),
+		handles: make(map[string]*handle),
+		tests:   make(map[string]int),
 	}
 	sql.Register("sqlmock", pool)
 }
@@ -27,21 +36,95 @@ type mockDriver struct {
 	sync.Mutex
 	counter int
 	conns   map[string]*sqlmock
+	handles map[string]*handle
+	tests   map[string]int // running tests bound by NewForTest
 }
 
 func (d *mockDriver) Open(dsn string) (driver.Conn, error) {
 	d.Lock()
 	defer d.Unlock()
 
+	if h, ok := d.handles[dsn]; ok {
+		if err := h.connect(h.tag); err != nil {
+			return nil, err
+		}
+		h.opened++
+		h.connsChanged(h.opened)
+		return h, nil
+	}
+
 	c, ok := d.conns[dsn]
 	if !ok {
 		return c, fmt.Errorf("expected a connection to be available, but it is not")
 	}
 
+	if err := c.connect(""); err != nil {
+		return nil, err
+	}
 	c.opened++
+	c.connsChanged(c.opened)
 	return c, nil
 }
 
+// remove deregisters the mock and all its handles, must be
+// called under the driver lock
+func (d *mockDriver) remove(c *sqlmock) {
+	if d.conns[c.dsn] == c {
+		delete(d.conns, c.dsn)
+	}
+	for _, dsn := range c.handles {
+		delete(d.handles, dsn)
+	}
+}
+
+// Deregister removes the mock from sqlmock driver, so that
+// it could be garbage collected and its dsn could be used
+// again. No more connections could be opened to the mock
+// afterwards.
+//
+// The mock is removed automatically, once its database is
+// closed, so there is no need to deregister it in that case.
+// An error is returned if the mock still has open connections,
+// which means its database was not closed.
+func Deregister(mock SqlmockCommon) error {
+	c, ok := mock.(*sqlmock)
+	if !ok {
+		return fmt.Errorf("cannot deregister %T, only sqlmock created mocks are supported", mock)
+	}
+
+	pool.Lock()
+	defer pool.Unlock()
+
+	pool.remove(c)
+	if c.opened > 0 {
+		return fmt.Errorf("mock database '%s' was deregistered having %d open connections, it should be closed after use", c.dsn, c.opened)
+	}
+	return nil
+}
+
+// CloseAll deregisters all the mocks from sqlmock driver. It is
+// meant to be called once all tests are finished, for example in
+// TestMain, to release mocks which databases were not closed. An
+// error lists all such mocks.
+func CloseAll() error {
+	pool.Lock()
+	defer pool.Unlock()
+
+	var open []string
+	for dsn, c := range pool.conns {
+		if c.opened > 0 {
+			open = append(open, fmt.Sprintf("'%s' (%d)", dsn, c.opened))
+		}
+		pool.remove(c)
+	}
+
+	if len(open) > 0 {
+		sort.Strings(open)
+		return fmt.Errorf("mock databases were not closed, having open connections: %s", strings.Join(open, ", "))
+	}
+	return nil
+}
+
 func SetDefaultMatchExpectationsInOrder(ordered bool) {
 	defaultOrdered = ordered
 }
@@ -50,6 +133,39 @@ func SetDefaultRequireExpectations(required bool) {
 	defaultRequire = required
 }
 
+func SetDefaultMatchPolicy(policy MatchPolicy) {
+	defaultPolicy = policy
+}
+
+// SetDefaultWarningsAsErrors promotes warnings to errors for every
+// mock created afterwards, so that a suite could turn it on by a
+// flag of its own, for example in TestMain.
+func SetDefaultWarningsAsErrors(strict bool) {
+	defaultStrictWarnings = strict
+}
+
+// SetDefaultRowsMiddleware sets middleware used by
+// every mock created afterwards, so that it could be
+// applied to a whole test suite.
+func SetDefaultRowsMiddleware(middleware ...RowsMiddleware) {
+	defaultMiddleware = middleware
+}
+
+// newMock creates a mock configured with defaults
+func newMock(dsn string) *sqlmock {
+	return &sqlmock{
+		dsn:                 dsn,
+		drv:                 pool,
+		ordered:             defaultOrdered,
+		policy:              defaultPolicy,
+		requireExpectations: defaultRequire,
+		strictWarnings:      defaultStrictWarnings,
+		middleware:          append([]RowsMiddleware(nil), defaultMiddleware...),
+		clock:               NewClock(time.Now()),
+		redact:              &redactions{},
+	}
+}
+
 // New creates sqlmock database connection
 // and a mock to manage expectations.
 // Pings db so that all expectations could be
@@ -59,7 +175,7 @@ func New() (*sql.DB, Sqlmock, error) {
 	dsn := fmt.Sprintf("sqlmock_db_%d", pool.counter)
 	pool.counter++
 
-	smock := &sqlmock{dsn: dsn, drv: pool, ordered: defaultOrdered, requireExpectations: defaultRequire}
+	smock := newMock(dsn)
 	pool.conns[dsn] = smock
 	pool.Unlock()
 
@@ -85,9 +201,104 @@ func NewWithDSN(dsn string) (*sql.DB, Sqlmock, error) {
 		pool.Unlock()
 		return nil, nil, fmt.Errorf("cannot create a new mock database with the same dsn: %s", dsn)
 	}
-	smock := &sqlmock{dsn: dsn, drv: pool, ordered: defaultOrdered, requireExpectations: defaultRequire}
+	smock := newMock(dsn)
 	pool.conns[dsn] = smock
 	pool.Unlock()
 
 	return smock.open()
 }
+
+// TestingT is the part of *testing.T used by NewForTest
+type TestingT interface {
+	Name() string
+	Cleanup(func())
+	Fatalf(format string, args ...interface{})
+}
+
+// NewForTest creates sqlmock database connection and a mock to
+// manage expectations, which are isolated to the test t. The dsn
+// is derived from the name of the test, including subtests, so
+// that the mock could be identified, and it never collides with
+// other tests, even when run in parallel.
+//
+// The database is closed and the mock is deregistered once the
+// test and all its subtests complete. Calls which still reach the
+// mock afterwards, like through a transaction or a statement leaked
+// to another test by a shared global, fail with an error naming the
+// finished test and the tests still running.
+func NewForTest(t TestingT) (*sql.DB, Sqlmock) {
+	pool.Lock()
+	dsn := fmt.Sprintf("sqlmock_%s_%d", t.Name(), pool.counter)
+	pool.counter++
+
+	smock := newMock(dsn)
+	smock.test = t.Name()
+	pool.conns[dsn] = smock
+	pool.tests[smock.test]++
+	pool.Unlock()
+
+	db, mock, err := smock.open()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Lock()
+		if pool.tests[smock.test]--; pool.tests[smock.test] <= 0 {
+			delete(pool.tests, smock.test)
+		}
+		pool.Unlock()
+
+		smock.Lock()
+		smock.testFinished = true
+		smock.Unlock()
+
+		db.Close()
+		Deregister(mock)
+	})
+	return db, mock
+}
+
+// guard fails calls made to the mock bound to a test, which finished
+func (c *sqlmock) guard(handle string) error {
+	c.Lock()
+	test, finished := c.test, c.testFinished
+	c.Unlock()
+
+	if !finished {
+		return nil
+	}
+
+	pool.Lock()
+	var running []string
+	for name := range pool.tests {
+		running = append(running, name)
+	}
+	pool.Unlock()
+
+	// subtests are reported instead of their parents
+	var leaves []string
+	for _, name := range running {
+		parent := false
+		for _, other := range running {
+			if strings.HasPrefix(other, name+"/") {
+				parent = true
+				break
+			}
+		}
+		if !parent {
+			leaves = append(leaves, "'"+name+"'")
+		}
+	}
+	sort.Strings(leaves)
+
+	msg := fmt.Sprintf("mock of test '%s' was called after the test finished", test)
+	switch len(leaves) {
+	case 0:
+	case 1:
+		msg += ", while test " + leaves[0] + " is running"
+	default:
+		msg += ", while tests " + strings.Join(leaves, ", ") + " are running"
+	}
+	return failf(handle, "%s, it is likely shared between tests", msg)
+}
@@ -1,7 +1,10 @@
 package sqlmock
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/jinzhu/gorm"
@@ -115,3 +118,125 @@ func TestTwoOpenConnectionsOnTheSameDSN(t *testing.T) {
 		t.Errorf("expected not the same mock instance, but it is the same")
 	}
 }
+
+func TestDeregister(t *testing.T) {
+	db, mock, err := NewWithDSN("deregistered dsn")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+
+	if err = Deregister(mock); err == nil {
+		t.Errorf("expected an error since database was not closed")
+	}
+
+	if _, ok := pool.conns["deregistered dsn"]; ok {
+		t.Errorf("expected mock to be removed from pool")
+	}
+
+	mock.ExpectClose()
+	if err = db.Close(); err != nil {
+		t.Errorf("expected no error on close, but got: %s", err)
+	}
+
+	db, mock, err = NewWithDSN("deregistered dsn")
+	if err != nil {
+		t.Fatalf("expected dsn to be available again, but got: %s", err)
+	}
+
+	mock.ExpectClose()
+	if err = db.Close(); err != nil {
+		t.Errorf("expected no error on close, but got: %s", err)
+	}
+
+	if err = Deregister(mock); err != nil {
+		t.Errorf("expected no error for closed database, but got: %s", err)
+	}
+}
+
+func TestCloseAll(t *testing.T) {
+	_, _, err := NewWithDSN("not closed dsn")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+
+	err = CloseAll()
+	if err == nil || !strings.Contains(err.Error(), "'not closed dsn' (1)") {
+		t.Errorf("expected an error listing the database which was not closed, but got: %v", err)
+	}
+
+	if len(pool.conns) != 0 {
+		t.Errorf("expected no connections in pool, but there is: %d", len(pool.conns))
+	}
+}
+
+func TestNewForTest(t *testing.T) {
+	var mu sync.Mutex
+	var dsns []string
+
+	// the group completes once all parallel subtests complete
+	t.Run("group", func(t *testing.T) {
+		for _, name := range []string{"first", "second"} {
+			name := name
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				db, mock := NewForTest(t)
+
+				mock.ExpectQuery("SELECT name").WillReturnRows(NewRows([]string{"name"}).AddRow(name))
+
+				var got string
+				if err := db.QueryRow("SELECT name FROM tests").Scan(&got); err != nil {
+					t.Errorf("error '%s' was not expected while querying", err)
+				} else if got != name {
+					t.Errorf("expected the mock of the subtest to return %s, but got %s", name, got)
+				}
+
+				if err := mock.ExpectationsWereMet(); err != nil {
+					t.Errorf("there were unfulfilled expections: %s", err)
+				}
+
+				mu.Lock()
+				dsns = append(dsns, mock.(*sqlmock).dsn)
+				mu.Unlock()
+			})
+		}
+	})
+
+	pool.Lock()
+	defer pool.Unlock()
+	for _, dsn := range dsns {
+		if !strings.HasPrefix(dsn, "sqlmock_TestNewForTest/group/") {
+			t.Errorf("expected dsn %s to be derived from the test name", dsn)
+		}
+		if _, ok := pool.conns[dsn]; ok {
+			t.Errorf("expected mock %s to be deregistered after its test completed", dsn)
+		}
+	}
+	if len(dsns) != 2 {
+		t.Errorf("expected both subtests to create a mock, but got %d", len(dsns))
+	}
+}
+
+func TestNewForTestGuardsLeakedCalls(t *testing.T) {
+	var leaked *sql.Tx
+
+	t.Run("leaky", func(t *testing.T) {
+		db, mock := NewForTest(t)
+		mock.ExpectBegin()
+
+		var err error
+		if leaked, err = db.Begin(); err != nil {
+			t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+		}
+	})
+
+	t.Run("victim", func(t *testing.T) {
+		NewForTest(t)
+
+		_, err := leaked.Exec("UPDATE users SET name = ?", "bob")
+		expected := "mock of test 'TestNewForTestGuardsLeakedCalls/leaky' was called after the test finished, while test 'TestNewForTestGuardsLeakedCalls/victim' is running, it is likely shared between tests"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected an error about the call leaked from the finished test, but got: %v", err)
+		}
+		leaked.Rollback()
+	})
+}
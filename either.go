@@ -0,0 +1,68 @@
+package sqlmock
+
+import "sync"
+
+// Alternative is an expectation, which may be grouped with others
+// by Either, it is implemented by all expectations queued by the mock
+type Alternative interface {
+	Expected
+}
+
+// alternatives is a group of expectations, which is met by any of
+// them, its lock is taken after the lock of an expectation
+type alternatives struct {
+	sync.Mutex
+	chosen *commonExpectation
+}
+
+// Either groups queued expectations, so that meeting any of them
+// meets the group, for the code which runs one of equivalent queries,
+// depending on a feature flag:
+//
+//	sqlmock.Either(
+//		mock.ExpectQuery("SELECT (.+) FROM users WHERE id = ?"),
+//		mock.ExpectQuery("SELECT (.+) FROM users_v2 WHERE id = ?"),
+//	)
+//
+// The first call of an alternative chooses it, the others are
+// skipped then and are not matched anymore. In order, any of the
+// alternatives may be matched, once the group is next.
+func Either(first, second Alternative, more ...Alternative) {
+	group := &alternatives{}
+	for _, a := range append([]Alternative{first, second}, more...) {
+		e := a.common()
+		e.Lock()
+		e.group = group
+		e.Unlock()
+	}
+}
+
+// choose commits the group to the alternative, when it is called first
+func (g *alternatives) choose(e *commonExpectation) {
+	if g == nil {
+		return
+	}
+	g.Lock()
+	if g.chosen == nil {
+		g.chosen = e
+	}
+	g.Unlock()
+}
+
+// skips tells whether the group was met by another alternative than e
+func (g *alternatives) skips(e *commonExpectation) bool {
+	if g == nil {
+		return false
+	}
+	g.Lock()
+	defer g.Unlock()
+	return g.chosen != nil && g.chosen != e
+}
+
+// groupOf returns the group of the locked expectation, if any
+func groupOf(e expectation) *alternatives {
+	if a, ok := e.(Expected); ok {
+		return a.common().group
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEitherIsMetByAnyAlternative(t *testing.T) {
+	t.Parallel()
+	for _, table := range []string{"users", "users_v2"} {
+		db, mock, err := New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.MatchExpectationsInOrder(true)
+		Either(
+			mock.ExpectQuery("SELECT (.+) FROM users WHERE").WillReturnRows(NewRows([]string{"name"}).AddRow("bob")),
+			mock.ExpectQuery("SELECT (.+) FROM users_v2 WHERE").WillReturnRows(NewRows([]string{"name"}).AddRow("bob")),
+		)
+		mock.ExpectExec("DELETE FROM sessions").WillReturnResult(NewResult(0, 1))
+
+		var name string
+		if err := db.QueryRow("SELECT name FROM "+table+" WHERE id = ?", 1).Scan(&name); err != nil {
+			t.Errorf("error '%s' was not expected while querying %s", err, table)
+		}
+		if _, err := db.Exec("DELETE FROM sessions"); err != nil {
+			t.Errorf("error '%s' was not expected while deleting", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expections: %s", err)
+		}
+		db.Close()
+	}
+}
+
+func TestEitherSkipsOtherAlternatives(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	Either(
+		mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1)),
+		mock.ExpectExec("UPDATE users_v2").WillReturnResult(NewResult(0, 1)),
+	)
+
+	if _, err := db.Exec("UPDATE users_v2 SET name = ?", "bob"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+	if _, err := db.Exec("UPDATE users SET name = ?", "bob"); err == nil || !strings.Contains(err.Error(), "was not expected") {
+		t.Errorf("expected the skipped alternative not to be matched, but got: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestEitherUnmet(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	Either(mock.ExpectExec("UPDATE users"), mock.ExpectExec("UPDATE users_v2"))
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Errorf("expected an error, since neither alternative was met")
+	}
+}
@@ -0,0 +1,96 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+)
+
+// EventKind is a kind of mock activity reported by an Event
+type EventKind int
+
+// kinds of mock activity
+const (
+	QueryStarted EventKind = iota
+	QueryMatched
+	QueryFailed
+	ExecStarted
+	ExecMatched
+	ExecFailed
+	TxBegan
+	TxCommitted
+	TxRolledBack
+	TxFailed
+)
+
+var eventKindNames = [...]string{
+	"QueryStarted", "QueryMatched", "QueryFailed",
+	"ExecStarted", "ExecMatched", "ExecFailed",
+	"TxBegan", "TxCommitted", "TxRolledBack", "TxFailed",
+}
+
+func (k EventKind) String() string {
+	if int(k) < len(eventKindNames) {
+		return eventKindNames[k]
+	}
+	return "EventKind(unknown)"
+}
+
+// Event describes mock activity, it is delivered to subscribers
+// of the mock, see Subscribe
+type Event struct {
+	Kind   EventKind
+	Handle string // the handle call was made on, see NewWithReplicas
+	Query  string
+	Args   []driver.Value
+	Err    error
+
+	// Statement is the kind of the query, see ClassifyStatement
+	Statement StatementKind
+
+	// Annotations of the query, see ExtractAnnotations
+	Annotations map[string]string
+}
+
+func (c *sqlmock) Subscribe(buffer int) <-chan Event {
+	ch := make(chan Event, buffer)
+	c.Lock()
+	c.subscribers = append(c.subscribers, ch)
+	c.Unlock()
+	return ch
+}
+
+// emit delivers the event to all subscribers, which have
+// room for it in their channel buffer
+func (c *sqlmock) emit(ev Event) {
+	c.Lock()
+	defer c.Unlock()
+
+	if ev.Query != "" {
+		ev.Statement = ClassifyStatement(ev.Query)
+	}
+	c.annotate(&ev)
+	c.record(ev)
+	for _, ch := range c.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// emitResult delivers an event of the kind depending on the error
+func (c *sqlmock) emitResult(matched, failed EventKind, handle, query string, args []driver.Value, err error) {
+	kind := matched
+	if err != nil {
+		kind = failed
+	}
+	c.emit(Event{Kind: kind, Handle: handle, Query: query, Args: args, Err: err})
+}
+
+// unsubscribeAll closes channels of all subscribers, must be
+// called under the mock lock
+func (c *sqlmock) unsubscribeAll() {
+	for _, ch := range c.subscribers {
+		close(ch)
+	}
+	c.subscribers = nil
+}
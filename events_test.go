@@ -0,0 +1,45 @@
+package sqlmock
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	events := mock.Subscribe(10)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnError(fmt.Errorf("locked"))
+	mock.ExpectRollback()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err = tx.Exec("UPDATE users SET name = ?", "bob"); err == nil {
+		t.Error("an error was expected while updating users")
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Errorf("error '%s' was not expected while rolling back a transaction", err)
+	}
+	db.Close()
+
+	var kinds []string
+	for ev := range events {
+		kinds = append(kinds, ev.Kind.String())
+		if ev.Kind == ExecFailed && (ev.Query != "UPDATE users SET name = ?" || ev.Err == nil || ev.Args[0] != "bob") {
+			t.Errorf("unexpected exec failure event: %+v", ev)
+		}
+	}
+
+	expected := "[TxBegan ExecStarted ExecFailed TxRolledBack]"
+	if fmt.Sprint(kinds) != expected {
+		t.Errorf("expected events %s, but got %v", expected, kinds)
+	}
+}
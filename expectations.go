@@ -1,12 +1,12 @@
 package sqlmock
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
-	"reflect"
 	"regexp"
-	"strings"
 	"sync"
+	"time"
 )
 
 // Argument interface allows to match
@@ -19,21 +19,90 @@ type Argument interface {
 // an expectation interface
 type expectation interface {
 	fulfilled() bool
+	reusable() bool
 	Lock()
 	Unlock()
 	String() string
 }
 
+// an expectation which verifies how it was
+// used, after it has been triggered
+type verifiable interface {
+	verify() error
+}
+
+// Expected is any expectation queued by the mock, like *ExpectedQuery
+// or *ExpectedBegin, so that expectations could be related to each
+// other, see Either and MatchedWithin
+type Expected interface {
+	String() string
+	common() *commonExpectation
+}
+
 // common expectation struct
 // satisfies the expectation interface
 type commonExpectation struct {
 	sync.Mutex
 	triggered bool
 	err       error
+	priority  int
+	reuse     bool
+	times     int
+	calls     int
+	group     *alternatives
+	clock     *Clock
+	matchedAt time.Time
+	windows   []*window
+	step      string
+	stepIndex int
+	redact    *redactions
+}
+
+func (e *commonExpectation) common() *commonExpectation {
+	return e
+}
+
+// trigger records a call, the expectation is triggered once
+// it was called the expected number of times
+func (e *commonExpectation) trigger() {
+	e.calls++
+	if e.calls == 1 && e.clock != nil {
+		e.matchedAt = e.clock.Now()
+	}
+	e.triggered = e.calls >= e.times
+	e.group.choose(e)
 }
 
 func (e *commonExpectation) fulfilled() bool {
-	return e.triggered
+	return e.triggered && !e.reuse || e.group.skips(e)
+}
+
+func (e *commonExpectation) reusable() bool {
+	return e.reuse
+}
+
+func (e *commonExpectation) getPriority() int {
+	return e.priority
+}
+
+// status describes whether the expectation was met
+func (e *commonExpectation) status() string {
+	if e.step != "" {
+		return e.stepStatus() + ", in step '" + e.step + "'"
+	}
+	return e.stepStatus()
+}
+
+func (e *commonExpectation) stepStatus() string {
+	switch {
+	case e.reuse:
+		return "reusable"
+	case e.triggered:
+		return "met"
+	case e.group.skips(e):
+		return "skipped, since an alternative was chosen"
+	}
+	return "pending"
 }
 
 // ExpectedClose is used to manage *sql.DB.Close expectation
@@ -54,13 +123,44 @@ func (e *ExpectedClose) String() string {
 	if e.err != nil {
 		msg += fmt.Sprintf(", which should return error: %s", e.err)
 	}
-	return msg
+	return msg + " (" + e.status() + ")"
+}
+
+// ExpectedConnect is used to manage a connection opened again, after
+// the previous one expired, returned by *Sqlmock.ExpectConnect.
+type ExpectedConnect struct {
+	commonExpectation
+}
+
+// WillReturnError allows to set an error for the connection attempt
+func (e *ExpectedConnect) WillReturnError(err error) *ExpectedConnect {
+	e.err = err
+	return e
+}
+
+// String returns string representation
+func (e *ExpectedConnect) String() string {
+	msg := "ExpectedConnect => expecting database Connect"
+	if e.err != nil {
+		msg += fmt.Sprintf(", which should return error: %s", e.err)
+	}
+	return msg + " (" + e.status() + ")"
 }
 
 // ExpectedBegin is used to manage *sql.DB.Begin expectation
 // returned by *Sqlmock.ExpectBegin.
 type ExpectedBegin struct {
 	commonExpectation
+	readOnly bool
+}
+
+// ReadOnly makes the transaction to be read-only, so that any
+// INSERT, UPDATE, DELETE, MERGE or TRUNCATE statement within it
+// fails, the same way it does in postgres. A transaction begun with
+// sql.TxOptions.ReadOnly is read-only regardless.
+func (e *ExpectedBegin) ReadOnly() *ExpectedBegin {
+	e.readOnly = true
+	return e
 }
 
 // WillReturnError allows to set an error for *sql.DB.Begin action
@@ -72,10 +172,13 @@ func (e *ExpectedBegin) WillReturnError(err error) *ExpectedBegin {
 // String returns string representation
 func (e *ExpectedBegin) String() string {
 	msg := "ExpectedBegin => expecting database transaction Begin"
+	if e.readOnly {
+		msg += " of read-only transaction"
+	}
 	if e.err != nil {
 		msg += fmt.Sprintf(", which should return error: %s", e.err)
 	}
-	return msg
+	return msg + " (" + e.status() + ")"
 }
 
 // ExpectedCommit is used to manage *sql.Tx.Commit expectation
@@ -96,7 +199,7 @@ func (e *ExpectedCommit) String() string {
 	if e.err != nil {
 		msg += fmt.Sprintf(", which should return error: %s", e.err)
 	}
-	return msg
+	return msg + " (" + e.status() + ")"
 }
 
 // ExpectedRollback is used to manage *sql.Tx.Rollback expectation
@@ -117,7 +220,7 @@ func (e *ExpectedRollback) String() string {
 	if e.err != nil {
 		msg += fmt.Sprintf(", which should return error: %s", e.err)
 	}
-	return msg
+	return msg + " (" + e.status() + ")"
 }
 
 // ExpectedQuery is used to manage *sql.DB.Query, *dql.DB.QueryRow, *sql.Tx.Query,
@@ -125,7 +228,14 @@ func (e *ExpectedRollback) String() string {
 // Returned by *Sqlmock.ExpectQuery.
 type ExpectedQuery struct {
 	queryBasedExpectation
-	rows driver.Rows
+	rows  driver.Rows
+	sets  []driver.Rows
+	stale driver.Rows
+
+	perCall     bool
+	queryRow    bool
+	rowsFetched int
+	rowsClosed  bool
 }
 
 // WithArgs will match given expected args to actual database query arguments.
@@ -136,6 +246,54 @@ func (e *ExpectedQuery) WithArgs(args ...driver.Value) *ExpectedQuery {
 	return e
 }
 
+// Priority sets the priority of this expectation, when expectations
+// are not matched in order. If several pending expectations match
+// a query, only the ones having the highest priority are considered
+// by the match policy. By default the priority is 0.
+func (e *ExpectedQuery) Priority(n int) *ExpectedQuery {
+	e.priority = n
+	return e
+}
+
+// Reusable allows this expectation to be matched any number of times,
+// including none. It does not take part in ordering and is matched
+// only when no other expectation matches the query. That is handy
+// for background queries like keepalives.
+func (e *ExpectedQuery) Reusable() *ExpectedQuery {
+	e.reuse = true
+	return e
+}
+
+// Times makes this expectation to be met only after it was
+// matched n times. Together with RowSetsPerCall, every call may
+// return a different set of rows.
+func (e *ExpectedQuery) Times(n int) *ExpectedQuery {
+	e.times = n
+	return e
+}
+
+// OnHandle binds the expectation to the database handle with the
+// given tag, like "primary" or "replica-1", so that only a query
+// made on that handle matches it. See NewWithReplicas.
+func (e *ExpectedQuery) OnHandle(tag string) *ExpectedQuery {
+	e.onHandle = tag
+	return e
+}
+
+// WillDelayFor allows to specify duration for which it will delay
+// result.
+func (e *ExpectedQuery) WillDelayFor(duration time.Duration) *ExpectedQuery {
+	e.delay = duration
+	return e
+}
+
+// WillDelayBy delays the result by a duration drawn from the
+// latency profile, instead of the profile used by the mock
+func (e *ExpectedQuery) WillDelayBy(profile *LatencyProfile) *ExpectedQuery {
+	e.latency = profile
+	return e
+}
+
 // WillReturnError allows to set an error for expected database query
 func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
 	e.err = err
@@ -143,48 +301,116 @@ func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
 }
 
 // WillReturnRows specifies the set of resulting rows that will be returned
-// by the triggered query
-func (e *ExpectedQuery) WillReturnRows(rows driver.Rows) *ExpectedQuery {
-	e.rows = rows
+// by the triggered query. Several sets of rows are returned as multiple
+// result sets, which are advanced by sql.Rows.NextResultSet, unless
+// RowSetsPerCall is used.
+func (e *ExpectedQuery) WillReturnRows(sets ...driver.Rows) *ExpectedQuery {
+	e.rows, e.sets = nil, sets
+	if len(sets) > 0 {
+		e.rows = sets[0]
+	}
+	return e
+}
+
+// WillReturnStaleRows sets the rows returned by a replica, which lags
+// behind the primary, see ReplicationLag, instead of the rows set by
+// WillReturnRows. By default a lagging replica returns no rows.
+func (e *ExpectedQuery) WillReturnStaleRows(rows driver.Rows) *ExpectedQuery {
+	e.stale = rows
 	return e
 }
 
+// staleRows returns the rows of a lagging replica instead of the sets
+func (e *ExpectedQuery) staleRows(sets []driver.Rows) []driver.Rows {
+	if e.stale != nil {
+		return []driver.Rows{e.stale}
+	}
+	return []driver.Rows{NewRows(sets[0].Columns())}
+}
+
+// RowSetsPerCall makes the sets of rows given to WillReturnRows to be
+// returned by successive calls of the expectation, one set per call,
+// instead of as result sets of every call. Once all the sets were
+// returned, further calls return the last one.
+func (e *ExpectedQuery) RowSetsPerCall() *ExpectedQuery {
+	e.perCall = true
+	return e
+}
+
+// rowsFor returns the sets of rows, which the given call of the
+// expectation, counted from zero, should return
+func (e *ExpectedQuery) rowsFor(call int) []driver.Rows {
+	if !e.perCall {
+		return e.sets
+	}
+	if call >= len(e.sets) {
+		call = len(e.sets) - 1
+	}
+	return e.sets[call : call+1]
+}
+
 // String returns string representation
 func (e *ExpectedQuery) String() string {
 	msg := "ExpectedQuery => expecting Query or QueryRow which:"
-	msg += "\n  - matches sql: '" + e.sqlRegex.String() + "'"
-
-	if len(e.args) == 0 {
-		msg += "\n  - is without arguments"
-	} else {
-		msg += "\n  - is with arguments:\n"
-		for i, arg := range e.args {
-			msg += fmt.Sprintf("    %d - %+v\n", i, arg)
-		}
-		msg = strings.TrimSpace(msg)
+	if e.queryRow {
+		msg = "ExpectedQuery => expecting QueryRow which:"
 	}
+	msg += e.describe()
+
+	for i, set := range e.sets {
+		what := "rows"
+		switch {
+		case e.perCall:
+			what = fmt.Sprintf("on call %d rows", i+1)
+		case len(e.sets) > 1:
+			what = fmt.Sprintf("result set %d", i+1)
+		}
 
-	if e.rows != nil {
-		msg += "\n  - should return rows:\n"
-		rs, _ := e.rows.(*rows)
-		for i, row := range rs.rows {
-			msg += fmt.Sprintf("    %d - %+v\n", i, row)
+		if rs, ok := set.(*rows); ok {
+			msg += fmt.Sprintf("\n  - should return %s of columns %v:", what, rs.cols)
+			for i, row := range rs.rows {
+				msg += fmt.Sprintf("\n    %d - %+v", i, row)
+			}
+			if len(rs.rows) == 0 {
+				msg += "\n    none"
+			}
+			if rs.closeErr != nil {
+				msg += fmt.Sprintf("\n  - should return error on rows Close: %s", rs.closeErr)
+			}
+		} else if set != nil {
+			msg += fmt.Sprintf("\n  - should return %s: %T", what, set)
 		}
-		msg = strings.TrimSpace(msg)
 	}
 
 	if e.err != nil {
 		msg += fmt.Sprintf("\n  - should return error: %s", e.err)
 	}
 
-	return msg
+	return msg + "\n  - is " + e.status()
+}
+
+func (e *ExpectedQuery) verify() error {
+	e.Lock()
+	defer e.Unlock()
+
+	if !e.queryRow || !e.triggered || e.err != nil {
+		return nil
+	}
+	if e.rowsFetched > 1 {
+		return fmt.Errorf("expected at most one row to be fetched, but %d rows were fetched for: %s", e.rowsFetched, e)
+	}
+	if !e.rowsClosed {
+		return fmt.Errorf("expected rows to be closed, but they were not for: %s", e)
+	}
+	return nil
 }
 
 // ExpectedExec is used to manage *sql.DB.Exec, *sql.Tx.Exec or *sql.Stmt.Exec expectations.
 // Returned by *Sqlmock.ExpectExec.
 type ExpectedExec struct {
 	queryBasedExpectation
-	result driver.Result
+	result   driver.Result
+	deadlock *deadlock
 }
 
 // WithArgs will match given expected args to actual database exec operation arguments.
@@ -195,6 +421,52 @@ func (e *ExpectedExec) WithArgs(args ...driver.Value) *ExpectedExec {
 	return e
 }
 
+// Priority sets the priority of this expectation, when expectations
+// are not matched in order. If several pending expectations match
+// an exec, only the ones having the highest priority are considered
+// by the match policy. By default the priority is 0.
+func (e *ExpectedExec) Priority(n int) *ExpectedExec {
+	e.priority = n
+	return e
+}
+
+// Reusable allows this expectation to be matched any number of times,
+// including none. It does not take part in ordering and is matched
+// only when no other expectation matches the exec.
+func (e *ExpectedExec) Reusable() *ExpectedExec {
+	e.reuse = true
+	return e
+}
+
+// Times makes this expectation to be met only after it was
+// matched n times.
+func (e *ExpectedExec) Times(n int) *ExpectedExec {
+	e.times = n
+	return e
+}
+
+// OnHandle binds the expectation to the database handle with the
+// given tag, like "primary" or "replica-1", so that only a exec
+// made on that handle matches it. See NewWithReplicas.
+func (e *ExpectedExec) OnHandle(tag string) *ExpectedExec {
+	e.onHandle = tag
+	return e
+}
+
+// WillDelayFor allows to specify duration for which it will delay
+// result.
+func (e *ExpectedExec) WillDelayFor(duration time.Duration) *ExpectedExec {
+	e.delay = duration
+	return e
+}
+
+// WillDelayBy delays the result by a duration drawn from the
+// latency profile, instead of the profile used by the mock
+func (e *ExpectedExec) WillDelayBy(profile *LatencyProfile) *ExpectedExec {
+	e.latency = profile
+	return e
+}
+
 // WillReturnError allows to set an error for expected database exec action
 func (e *ExpectedExec) WillReturnError(err error) *ExpectedExec {
 	e.err = err
@@ -204,34 +476,31 @@ func (e *ExpectedExec) WillReturnError(err error) *ExpectedExec {
 // String returns string representation
 func (e *ExpectedExec) String() string {
 	msg := "ExpectedExec => expecting Exec which:"
-	msg += "\n  - matches sql: '" + e.sqlRegex.String() + "'"
+	msg += e.describe()
 
-	if len(e.args) == 0 {
-		msg += "\n  - is without arguments"
-	} else {
-		msg += "\n  - is with arguments:\n"
-		var margs []string
-		for i, arg := range e.args {
-			margs = append(margs, fmt.Sprintf("    %d - %+v", i, arg))
-		}
-		msg += strings.Join(margs, "\n")
-	}
-
-	if e.result != nil {
-		res, _ := e.result.(*result)
+	if e.result == driver.ResultNoRows {
+		msg += "\n  - should return Result of a DDL statement without rows"
+	} else if res, ok := e.result.(*result); ok {
 		msg += "\n  - should return Result having:"
 		msg += fmt.Sprintf("\n      LastInsertId: %d", res.insertID)
 		msg += fmt.Sprintf("\n      RowsAffected: %d", res.rowsAffected)
 		if res.err != nil {
 			msg += fmt.Sprintf("\n      Error: %s", res.err)
 		}
+	} else if res, ok := e.result.(*warningsResult); ok {
+		msg += "\n  - should return Result having:"
+		msg += fmt.Sprintf("\n      LastInsertId: %d", res.insertID)
+		msg += fmt.Sprintf("\n      RowsAffected: %d", res.rowsAffected)
+		msg += fmt.Sprintf("\n      Warnings: %d", len(res.warnings))
+	} else if e.result != nil {
+		msg += fmt.Sprintf("\n  - should return Result: %T", e.result)
 	}
 
 	if e.err != nil {
 		msg += fmt.Sprintf("\n  - should return error: %s", e.err)
 	}
 
-	return msg
+	return msg + "\n  - is " + e.status()
 }
 
 // WillReturnResult arranges for an expected Exec() to return a particular
@@ -243,6 +512,65 @@ func (e *ExpectedExec) WillReturnResult(result driver.Result) *ExpectedExec {
 	return e
 }
 
+// WillReturnResultNoRows arranges for an expected Exec() to return
+// driver.ResultNoRows, as drivers do for DDL statements, so that
+// both LastInsertId and RowsAffected of the result return an error.
+func (e *ExpectedExec) WillReturnResultNoRows() *ExpectedExec {
+	e.result = driver.ResultNoRows
+	return e
+}
+
+// deadlock coordinates two conflicting statements
+type deadlock struct {
+	sync.Mutex
+	arrived int
+	release chan struct{}
+	timeout time.Duration
+	first   *regexp.Regexp
+	second  *regexp.Regexp
+}
+
+// deadlockTimeout bounds the wait of the first arrived statement
+// for the conflicting one, so that a test fails instead of hanging
+const deadlockTimeout = time.Second
+
+// wait blocks the first arrived statement until the conflicting
+// one arrives, which is then chosen as a deadlock victim. It fails
+// if the conflicting statement does not arrive within the timeout.
+func (d *deadlock) wait(sqlRegex *regexp.Regexp) error {
+	d.Lock()
+	d.arrived++
+	if d.arrived > 1 {
+		if d.arrived == 2 {
+			close(d.release)
+		}
+		d.Unlock()
+		return ErrDeadlock
+	}
+	d.Unlock()
+
+	select {
+	case <-d.release:
+		return nil
+	case <-time.After(d.timeout):
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	select {
+	case <-d.release:
+		return nil // arrived meanwhile
+	default:
+	}
+	d.arrived--
+
+	other := d.second
+	if sqlRegex == d.second {
+		other = d.first
+	}
+	return fmt.Errorf("deadlock was expected, but the conflicting statement, which matches [%s], was not executed within %s", other, d.timeout)
+}
+
 // ExpectedPrepare is used to manage *sql.DB.Prepare or *sql.Tx.Prepare expectations.
 // Returned by *Sqlmock.ExpectPrepare.
 type ExpectedPrepare struct {
@@ -251,6 +579,94 @@ type ExpectedPrepare struct {
 	sqlRegex  *regexp.Regexp
 	statement driver.Stmt
 	closeErr  error
+
+	key            string
+	executions     int
+	wantExecutions int
+	hasExecutions  bool
+
+	argSets     [][]driver.Value
+	wantArgSets [][]driver.Value
+	hasArgSets  bool
+}
+
+// WithKey labels the prepared statement with a key, like the one
+// it is cached by, and requires it to be prepared exactly once.
+// Preparing a query, which matches it, again fails, since the
+// statement cache was missed.
+func (e *ExpectedPrepare) WithKey(key string) *ExpectedPrepare {
+	e.key = key
+	return e
+}
+
+// WillBeExecutedTimes expects the prepared statement to be executed
+// by Exec() or Query() exactly n times, which is verified by
+// ExpectationsWereMet. Together with WithKey, it asserts that the
+// statement is prepared once and then reused from a cache.
+func (e *ExpectedPrepare) WillBeExecutedTimes(n int) *ExpectedPrepare {
+	e.wantExecutions, e.hasExecutions = n, true
+	return e
+}
+
+// WillBeExecutedWithArgSets expects the prepared statement to be executed
+// by Exec() or Query() once with every given set of arguments, in any
+// order, which is verified by ExpectationsWereMet. The sets are compared
+// as a multiset, for batch code which executes them nondeterministically.
+func (e *ExpectedPrepare) WillBeExecutedWithArgSets(sets ...[]driver.Value) *ExpectedPrepare {
+	e.wantArgSets, e.hasArgSets = sets, true
+	return e
+}
+
+// executed records an execution of the statement with args
+func (e *ExpectedPrepare) executed(args []driver.Value) {
+	e.Lock()
+	e.executions++
+	if e.hasArgSets {
+		e.argSets = append(e.argSets, append([]driver.Value(nil), args...))
+	}
+	e.Unlock()
+}
+
+func (e *ExpectedPrepare) verify() error {
+	e.Lock()
+	defer e.Unlock()
+
+	if !e.triggered {
+		return nil
+	}
+	name := e.key
+	if name == "" {
+		name = e.sqlRegex.String()
+	}
+	if e.hasExecutions && e.executions != e.wantExecutions {
+		return fmt.Errorf("prepared statement '%s' was executed %d times, but expected to be executed %d times", name, e.executions, e.wantExecutions)
+	}
+	if e.hasArgSets && !argSetsMatch(e.wantArgSets, e.argSets) {
+		return fmt.Errorf("prepared statement '%s' was executed with argument sets %+v, but expected to be executed with %+v in any order", name, e.redact.argSets(e.argSets), e.redact.argSets(e.wantArgSets))
+	}
+	return nil
+}
+
+// argSetsMatch compares sets of arguments as multisets, every actual
+// set has to match a distinct expected one
+func argSetsMatch(expected, actual [][]driver.Value) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	used := make([]bool, len(expected))
+	for _, args := range actual {
+		found := false
+		for i, want := range expected {
+			if !used[i] && CompareArgs(want, args) == nil {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 // WillReturnError allows to set an error for the expected *sql.DB.Prepare or *sql.Tx.Prepare action.
@@ -265,12 +681,18 @@ func (e *ExpectedPrepare) WillReturnCloseError(err error) *ExpectedPrepare {
 	return e
 }
 
+// distance measures how far the prepared query is from matching the
+// expectation, see regexDistance
+func (e *ExpectedPrepare) distance(sql string, args []driver.Value) int {
+	return regexDistance(e.sqlRegex, sql)
+}
+
 // ExpectQuery allows to expect Query() or QueryRow() on this prepared statement.
 // this method is convenient in order to prevent duplicating sql query string matching.
 func (e *ExpectedPrepare) ExpectQuery() *ExpectedQuery {
 	eq := &ExpectedQuery{}
 	eq.sqlRegex = e.sqlRegex
-	e.mock.expected = append(e.mock.expected, eq)
+	e.mock.expect(eq)
 	return eq
 }
 
@@ -279,7 +701,7 @@ func (e *ExpectedPrepare) ExpectQuery() *ExpectedQuery {
 func (e *ExpectedPrepare) ExpectExec() *ExpectedExec {
 	eq := &ExpectedExec{}
 	eq.sqlRegex = e.sqlRegex
-	e.mock.expected = append(e.mock.expected, eq)
+	e.mock.expect(eq)
 	return eq
 }
 
@@ -288,6 +710,19 @@ func (e *ExpectedPrepare) String() string {
 	msg := "ExpectedPrepare => expecting Prepare statement which:"
 	msg += "\n  - matches sql: '" + e.sqlRegex.String() + "'"
 
+	if e.key != "" {
+		msg += "\n  - is prepared once with key: " + e.key
+	}
+	if e.hasExecutions {
+		msg += fmt.Sprintf("\n  - should be executed %d times, executed %d times", e.wantExecutions, e.executions)
+	}
+	if e.hasArgSets {
+		msg += "\n  - should be executed with argument sets in any order:"
+		for i, args := range e.redact.argSets(e.wantArgSets) {
+			msg += fmt.Sprintf("\n    %d - %+v", i, args)
+		}
+	}
+
 	if e.err != nil {
 		msg += fmt.Sprintf("\n  - should return error: %s", e.err)
 	}
@@ -296,7 +731,7 @@ func (e *ExpectedPrepare) String() string {
 		msg += fmt.Sprintf("\n  - should return error on Close: %s", e.closeErr)
 	}
 
-	return msg
+	return msg + "\n  - is " + e.status()
 }
 
 // query based expectation
@@ -305,65 +740,115 @@ type queryBasedExpectation struct {
 	commonExpectation
 	sqlRegex *regexp.Regexp
 	args     []driver.Value
+	delay    time.Duration
+	latency  *LatencyProfile
+	onHandle string
+	timeout  time.Duration
+	timesOut bool
+	ctxCheck func(ctx context.Context) error
+}
+
+// handleMatches tells whether the call made on handle is expected
+func (e *queryBasedExpectation) handleMatches(handle string) bool {
+	return e.onHandle == "" || e.onHandle == handle
+}
+
+// describe lists the sql, arguments and call settings
+// of the expectation for its string representation
+func (e *queryBasedExpectation) describe() string {
+	msg := "\n  - matches sql: '" + e.sqlRegex.String() + "'"
+
+	switch {
+	case e.args == nil:
+		msg += "\n  - is with any arguments"
+	case len(e.args) == 0:
+		msg += "\n  - is without arguments"
+	default:
+		msg += "\n  - is with arguments:"
+		for i, arg := range e.redact.args(e.args) {
+			msg += fmt.Sprintf("\n    %d - %+v", i, arg)
+		}
+	}
+
+	if e.priority != 0 {
+		msg += fmt.Sprintf("\n  - has priority: %d", e.priority)
+	}
+	if e.reuse {
+		msg += "\n  - may be called any number of times"
+	}
+	if e.times > 1 {
+		msg += fmt.Sprintf("\n  - should be called %d times, was called %d", e.times, e.calls)
+	}
+	if e.onHandle != "" {
+		msg += "\n  - is made on handle: " + e.onHandle
+	}
+	if e.timesOut {
+		msg += fmt.Sprintf("\n  - should time out after: %s", e.timeout)
+	} else if e.latency != nil {
+		msg += "\n  - should be delayed by a latency profile"
+	} else if e.delay > 0 {
+		msg += fmt.Sprintf("\n  - should be delayed for: %s", e.delay)
+	}
+	if e.ctxCheck != nil {
+		msg += "\n  - should be called with a context passing a check"
+	}
+	return msg
 }
 
-func (e *queryBasedExpectation) attemptMatch(sql string, args []driver.Value) (ret bool) {
-	if !e.queryMatches(sql) {
-		return
+// delayFor returns how long the call should be delayed, the
+// profile is used if the expectation does not specify a delay
+func (e *queryBasedExpectation) delayFor(profile *LatencyProfile) time.Duration {
+	switch {
+	case e.latency != nil:
+		return e.latency.Next()
+	case e.delay > 0:
+		return e.delay
+	case profile != nil:
+		return profile.Next()
 	}
+	return 0
+}
 
-	defer recover() // ignore panic since we attempt a match
+func (e *queryBasedExpectation) attemptMatch(sql string, args []driver.Value, n normalizers) bool {
+	return e.queryMatches(sql) && e.argsMismatch(args, n) == nil
+}
 
-	if e.argsMatches(args) {
-		return true
+func (e *queryBasedExpectation) specificity() int {
+	score := len(e.sqlRegex.String())
+	for _, arg := range e.args {
+		if _, ok := arg.(Argument); ok {
+			score += 1000
+			continue
+		}
+		score += 2000
 	}
-	return
+	return score
+}
+
+// distance measures how far the call is from matching the expectation,
+// which is the edit distance between the query and sql regexp, see
+// regexDistance, increased by the difference in argument count
+func (e *queryBasedExpectation) distance(sql string, args []driver.Value) int {
+	dist := regexDistance(e.sqlRegex, sql)
+	if e.args != nil {
+		if len(args) > len(e.args) {
+			dist += len(args) - len(e.args)
+		} else {
+			dist += len(e.args) - len(args)
+		}
+	}
+	return dist
 }
 
 func (e *queryBasedExpectation) queryMatches(sql string) bool {
 	return e.sqlRegex.MatchString(sql)
 }
 
-func (e *queryBasedExpectation) argsMatches(args []driver.Value) bool {
+// argsMismatch tells why args do not match the expected ones,
+// both are normalized by n before comparison
+func (e *queryBasedExpectation) argsMismatch(args []driver.Value, n normalizers) *ArgMismatch {
 	if nil == e.args {
-		return true
+		return nil
 	}
-	if len(args) != len(e.args) {
-		return false
-	}
-	for k, v := range args {
-		matcher, ok := e.args[k].(Argument)
-		if ok {
-			if !matcher.Match(v) {
-				return false
-			}
-			continue
-		}
-		vi := reflect.ValueOf(v)
-		ai := reflect.ValueOf(e.args[k])
-		switch vi.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			if vi.Int() != ai.Int() {
-				return false
-			}
-		case reflect.Float32, reflect.Float64:
-			if vi.Float() != ai.Float() {
-				return false
-			}
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			if vi.Uint() != ai.Uint() {
-				return false
-			}
-		case reflect.String:
-			if vi.String() != ai.String() {
-				return false
-			}
-		default:
-			// compare types like time.Time based on type only
-			if vi.Kind() != ai.Kind() {
-				return false
-			}
-		}
-	}
-	return true
+	return compareArgs(e.args, args, n)
 }
@@ -18,29 +18,29 @@ func (m matcher) Match(driver.Value) bool {
 func TestQueryExpectationArgComparison(t *testing.T) {
 	e := &queryBasedExpectation{}
 	against := []driver.Value{5}
-	if !e.argsMatches(against) {
+	if e.argsMismatch(against, nil) != nil {
 		t.Error("arguments should match, since the no expectation was set")
 	}
 
 	e.args = []driver.Value{5, "str"}
 
 	against = []driver.Value{5}
-	if e.argsMatches(against) {
+	if e.argsMismatch(against, nil) == nil {
 		t.Error("arguments should not match, since the size is not the same")
 	}
 
 	against = []driver.Value{3, "str"}
-	if e.argsMatches(against) {
+	if e.argsMismatch(against, nil) == nil {
 		t.Error("arguments should not match, since the first argument (int value) is different")
 	}
 
 	against = []driver.Value{5, "st"}
-	if e.argsMatches(against) {
+	if e.argsMismatch(against, nil) == nil {
 		t.Error("arguments should not match, since the second argument (string value) is different")
 	}
 
 	against = []driver.Value{5, "str"}
-	if !e.argsMatches(against) {
+	if e.argsMismatch(against, nil) != nil {
 		t.Error("arguments should match, but it did not")
 	}
 
@@ -50,13 +50,13 @@ func TestQueryExpectationArgComparison(t *testing.T) {
 	tm, _ := time.Parse(longForm, "Feb 3, 2013 at 7:54pm (PST)")
 
 	against = []driver.Value{5, tm}
-	if !e.argsMatches(against) {
+	if e.argsMismatch(against, nil) != nil {
 		t.Error("arguments should match (time will be compared only by type), but it did not")
 	}
 
 	against = []driver.Value{5, matcher{}}
-	if !e.argsMatches(against) {
-		t.Error("arguments should match, but it did not")
+	if e.argsMismatch(against, nil) == nil {
+		t.Error("arguments should not match, since the struct types differ")
 	}
 }
 
@@ -82,3 +82,41 @@ func ExampleExpectExec() {
 	fmt.Println(err)
 	// Output: some error
 }
+
+func TestExpectationString(t *testing.T) {
+	e := (&ExpectedQuery{}).WillReturnRows(NewRows([]string{"id", "name"}).AddRow(1, "bob"))
+	e.sqlRegex = regexp.MustCompile("SELECT (.+) FROM users")
+	e.WithArgs(5).Priority(2).WillDelayFor(time.Second).Reusable()
+
+	expected := `ExpectedQuery => expecting Query or QueryRow which:
+  - matches sql: 'SELECT (.+) FROM users'
+  - is with arguments:
+    0 - 5
+  - has priority: 2
+  - may be called any number of times
+  - should be delayed for: 1s
+  - should return rows of columns [id name]:
+    0 - [1 bob]
+  - is reusable`
+	if s := e.String(); s != expected {
+		t.Errorf("expected query expectation to be described as:\n%s\nbut got:\n%s", expected, s)
+	}
+
+	x := &ExpectedExec{}
+	x.sqlRegex = regexp.MustCompile("DROP TABLE users")
+	x.WillReturnResultNoRows()
+	x.triggered = true
+
+	expected = `ExpectedExec => expecting Exec which:
+  - matches sql: 'DROP TABLE users'
+  - is with any arguments
+  - should return Result of a DDL statement without rows
+  - is met`
+	if s := x.String(); s != expected {
+		t.Errorf("expected exec expectation to be described as:\n%s\nbut got:\n%s", expected, s)
+	}
+
+	if s := (&ExpectedCommit{}).WillReturnError(fmt.Errorf("failed")).String(); s != "ExpectedCommit => expecting transaction Commit, which should return error: failed (pending)" {
+		t.Errorf("unexpected commit expectation description: %s", s)
+	}
+}
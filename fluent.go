@@ -0,0 +1,149 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+)
+
+// Recorder queues expectations in the style of gomock, it is
+// returned by EXPECT and wraps the usual expectations:
+//
+//	mock.EXPECT().Query("SELECT (.+) FROM users").WithArgs(5).Return(rows)
+//	mock.EXPECT().Exec("UPDATE users").Return(sqlmock.NewResult(0, 1))
+type Recorder struct {
+	mock SqlmockCommon
+}
+
+func (c *sqlmock) EXPECT() *Recorder {
+	return &Recorder{mock: c}
+}
+
+// Begin expects a transaction to begin
+func (r *Recorder) Begin() *TxCall {
+	e := r.mock.ExpectBegin()
+	return &TxCall{func(err error) { e.WillReturnError(err) }}
+}
+
+// Commit expects a transaction to be committed
+func (r *Recorder) Commit() *TxCall {
+	e := r.mock.ExpectCommit()
+	return &TxCall{func(err error) { e.WillReturnError(err) }}
+}
+
+// Rollback expects a transaction to be rolled back
+func (r *Recorder) Rollback() *TxCall {
+	e := r.mock.ExpectRollback()
+	return &TxCall{func(err error) { e.WillReturnError(err) }}
+}
+
+// Close expects the database to be closed
+func (r *Recorder) Close() *TxCall {
+	e := r.mock.ExpectClose()
+	return &TxCall{func(err error) { e.WillReturnError(err) }}
+}
+
+// Prepare expects a statement matching sqlRegexStr to be prepared
+func (r *Recorder) Prepare(sqlRegexStr string) *PrepareCall {
+	return &PrepareCall{r.mock.ExpectPrepare(sqlRegexStr)}
+}
+
+// Query expects a query matching sqlRegexStr
+func (r *Recorder) Query(sqlRegexStr string) *QueryCall {
+	return &QueryCall{r.mock.ExpectQuery(sqlRegexStr)}
+}
+
+// Exec expects an exec matching sqlRegexStr
+func (r *Recorder) Exec(sqlRegexStr string) *ExecCall {
+	return &ExecCall{r.mock.ExpectExec(sqlRegexStr)}
+}
+
+// TxCall is an expected Begin, Commit, Rollback or Close call
+type TxCall struct {
+	willReturnError func(error)
+}
+
+// Return sets the error returned by the call
+func (c *TxCall) Return(err error) *TxCall {
+	c.willReturnError(err)
+	return c
+}
+
+// PrepareCall is an expected Prepare call
+type PrepareCall struct {
+	*ExpectedPrepare
+}
+
+// Return sets the error returned by the call
+func (c *PrepareCall) Return(err error) *PrepareCall {
+	c.WillReturnError(err)
+	return c
+}
+
+// QueryCall is an expected Query call
+type QueryCall struct {
+	*ExpectedQuery
+}
+
+// WithArgs sets the arguments the call is expected with
+func (c *QueryCall) WithArgs(args ...driver.Value) *QueryCall {
+	c.ExpectedQuery.WithArgs(args...)
+	return c
+}
+
+// Times sets how many times the call is expected
+func (c *QueryCall) Times(n int) *QueryCall {
+	c.ExpectedQuery.Times(n)
+	return c
+}
+
+// AnyTimes allows the call any number of times, including none
+func (c *QueryCall) AnyTimes() *QueryCall {
+	c.Reusable()
+	return c
+}
+
+// Return sets the rows returned by the call, see WillReturnRows
+func (c *QueryCall) Return(rows ...driver.Rows) *QueryCall {
+	c.WillReturnRows(rows...)
+	return c
+}
+
+// ReturnError sets the error returned by the call
+func (c *QueryCall) ReturnError(err error) *QueryCall {
+	c.WillReturnError(err)
+	return c
+}
+
+// ExecCall is an expected Exec call
+type ExecCall struct {
+	*ExpectedExec
+}
+
+// WithArgs sets the arguments the call is expected with
+func (c *ExecCall) WithArgs(args ...driver.Value) *ExecCall {
+	c.ExpectedExec.WithArgs(args...)
+	return c
+}
+
+// Times sets how many times the call is expected
+func (c *ExecCall) Times(n int) *ExecCall {
+	c.ExpectedExec.Times(n)
+	return c
+}
+
+// AnyTimes allows the call any number of times, including none
+func (c *ExecCall) AnyTimes() *ExecCall {
+	c.Reusable()
+	return c
+}
+
+// Return sets the result returned by the call
+func (c *ExecCall) Return(result driver.Result) *ExecCall {
+	c.WillReturnResult(result)
+	return c
+}
+
+// ReturnError sets the error returned by the call
+func (c *ExecCall) ReturnError(err error) *ExecCall {
+	c.WillReturnError(err)
+	return c
+}
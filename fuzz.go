@@ -0,0 +1,80 @@
+package sqlmock
+
+import "regexp"
+
+// StripQuery normalizes the query the same way the mock does, before
+// it is matched against expectations, unless raw queries are enabled:
+// new lines and repeated whitespace are replaced by a single space
+// and the query is trimmed.
+func StripQuery(query string) string {
+	return stripQuery(query)
+}
+
+// MatchQuery tells whether the query is matched by the sql regexp the
+// same way an expectation queued by ExpectQuery or ExpectExec matches
+// it, so that custom matchers could be checked against the mock. It
+// returns an error instead of panicking, if the regexp is invalid.
+func MatchQuery(sqlRegexStr, query string) (bool, error) {
+	expr, err := regexp.Compile(sqlRegexStr)
+	if err != nil {
+		return false, err
+	}
+	return expr.MatchString(stripQuery(query)), nil
+}
+
+// fuzzCorpus are queries, which the parsers of the mock found hard,
+// like unbalanced quotes or parenthesis, comments and unicode
+var fuzzCorpus = []string{
+	"",
+	" ",
+	"SELECT",
+	"SELECT 1",
+	"select id, name from users where id = ?",
+	"SELECT  *\n\tFROM users\r\nWHERE id = $1",
+	"SELECT u.id, u.\"Name\" AS name FROM users u JOIN orders o ON o.user_id = u.id",
+	"SELECT count(*) total, coalesce(name, 'none, really') label FROM users GROUP BY name",
+	"SELECT id, (SELECT max(id) FROM orders) last_order FROM users",
+	"SELECT DISTINCT ON (id) id FROM users",
+	"SELECT 'unterminated FROM users",
+	"SELECT ((((id FROM users",
+	"SELECT id)))) FROM users",
+	"SELECT `id`, [name] FROM `users`",
+	"SELECT 'it''s', \"a\"\"b\" FROM t",
+	"SELECT pg_notify('chan', 'payload')",
+	"SELECT pg_advisory_lock(?)",
+	"SELECT GET_LOCK('lock', 10)",
+	"INSERT INTO users(name) VALUES(?), (?), (?) RETURNING id",
+	"INSERT INTO users VALUES ('a', 1.5, $2)",
+	"REPLACE INTO users(id) VALUES(1)",
+	"UPDATE users SET name = ? WHERE id IN (?, ?, ?)",
+	"DELETE FROM users WHERE name = 'x' -- comment",
+	"DELETE /* hint */ FROM users",
+	"CREATE TABLE users (id int)",
+	"WITH t AS (SELECT 1) SELECT * FROM t",
+	"LISTEN \"Chan\"",
+	"UNLISTEN *",
+	"NOTIFY chan, 'payload'",
+	"SELECT * FROM users /*controller='users',route='%2Fusers'*/",
+	"SELECT 'ünïcödé' FROM ✓",
+	"SELECT \x00 FROM \xff",
+	"((((((((((",
+	"'\"`[",
+	"/*/*/*",
+	";;;",
+}
+
+// FuzzCorpus returns the seed corpus of sql queries, which fuzz targets
+// of the mock start with, so that custom matchers or query rewriting
+// helpers could be fuzzed against the same queries:
+//
+//	func FuzzMatcher(f *testing.F) {
+//		for _, query := range sqlmock.FuzzCorpus() {
+//			f.Add(query)
+//		}
+//		f.Fuzz(func(t *testing.T, query string) {
+//			...
+//		})
+//	}
+func FuzzCorpus() []string {
+	return append([]string(nil), fuzzCorpus...)
+}
@@ -0,0 +1,75 @@
+//go:build go1.18
+// +build go1.18
+
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzStripQuery(f *testing.F) {
+	for _, query := range FuzzCorpus() {
+		f.Add(query)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		stripped := StripQuery(query)
+		if again := StripQuery(stripped); again != stripped {
+			t.Errorf("expected stripping to be idempotent, but '%s' was stripped to '%s'", stripped, again)
+		}
+		if isStripped(query) && stripped != query {
+			t.Errorf("expected query '%s' to be left as is, since it is stripped, but got '%s'", query, stripped)
+		}
+		if !utf8.ValidString(stripped) {
+			return // not a valid regexp, when quoted
+		}
+		if ok, err := MatchQuery(regexp.QuoteMeta(stripped), query); err != nil || !ok {
+			t.Errorf("expected query '%s' to match its quoted self, but got: %t, %v", query, ok, err)
+		}
+	})
+}
+
+func FuzzParsers(f *testing.F) {
+	for _, query := range FuzzCorpus() {
+		f.Add(query)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		// none of the parsers may panic
+		query = StripQuery(query)
+		fingerprint(query)
+		statementTables(query)
+		modifyingStatement(query)
+		selectedFunction(query)
+		recognizedKind(query)
+		parseNotification(query, nil)
+		SQLCommenter(query)
+		if columns, ok := selectedColumns(query); ok {
+			if err := checkColumns(query, columns); err != nil {
+				t.Errorf("expected query '%s' to select the columns it was parsed to select, but got: %s", query, err)
+			}
+		}
+		if kind := ClassifyStatement(query); kind != StatementOther {
+			if !regexp.MustCompile(Kind(kind)).MatchString(query) {
+				t.Errorf("expected query '%s' of %s kind to be matched by its kind regexp", query, kind)
+			}
+		}
+	})
+}
+
+func FuzzCompareArgs(f *testing.F) {
+	f.Add(int64(0), 0.0, "", []byte(nil), false)
+	f.Add(int64(-1), 1.5, "bob", []byte("bytes"), true)
+	f.Fuzz(func(t *testing.T, i int64, fl float64, s string, b []byte, flag bool) {
+		args := []driver.Value{i, fl, s, b, flag, nil}
+		copied := []driver.Value{i, fl, s, b, flag, nil}
+		if b != nil {
+			// an empty slice is not NULL, unlike a nil one
+			copied[3] = append([]byte{}, b...)
+		}
+		if mismatch := CompareArgs(args, copied); mismatch != nil {
+			t.Errorf("expected arguments %+v to match themselves, but got: %s", args, mismatch)
+		}
+	})
+}
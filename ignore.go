@@ -0,0 +1,63 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"regexp"
+)
+
+// IgnoredQueries is used to answer queries, which are not relevant
+// to a test, for example issued by a framework or an ORM, without
+// any expectation. Returned by *Sqlmock.IgnoreQueries.
+type IgnoredQueries struct {
+	sqlRegex []*regexp.Regexp
+	rows     driver.Rows
+	result   driver.Result
+}
+
+// WillReturnRows specifies the set of rows returned by ignored queries,
+// by default no rows are returned
+func (i *IgnoredQueries) WillReturnRows(rows driver.Rows) *IgnoredQueries {
+	i.rows = rows
+	return i
+}
+
+// WillReturnResult specifies the result returned by ignored execs,
+// by default it has no last insert id and no affected rows
+func (i *IgnoredQueries) WillReturnResult(result driver.Result) *IgnoredQueries {
+	i.result = result
+	return i
+}
+
+func (i *IgnoredQueries) matches(query string) bool {
+	for _, re := range i.sqlRegex {
+		if re.MatchString(query) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *sqlmock) IgnoreQueries(sqlRegexStr ...string) *IgnoredQueries {
+	i := &IgnoredQueries{rows: NewRows(nil), result: NewResult(0, 0)}
+	for _, s := range sqlRegexStr {
+		i.sqlRegex = append(i.sqlRegex, regexp.MustCompile(s))
+	}
+
+	c.Lock()
+	c.ignored = append(c.ignored, i)
+	c.Unlock()
+	return i
+}
+
+// ignoredQuery looks up the ignore rule matching the stripped query
+func (c *sqlmock) ignoredQuery(query string) *IgnoredQueries {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, i := range c.ignored {
+		if i.matches(query) {
+			return i
+		}
+	}
+	return nil
+}
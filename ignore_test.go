@@ -0,0 +1,52 @@
+package sqlmock
+
+import (
+	"testing"
+)
+
+func TestIgnoreQueries(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.RequireExpectations(true)
+	mock.IgnoreQueries("^SELECT VERSION\\(\\)").
+		WillReturnRows(NewRows([]string{"version"}).AddRow("8.0.32"))
+	mock.IgnoreQueries("^SET NAMES", "^SET SESSION")
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	for i := 0; i < 2; i++ {
+		var version string
+		if err = db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+			t.Fatalf("error '%s' was not expected while querying version", err)
+		}
+		if version != "8.0.32" {
+			t.Errorf("expected version to be 8.0.32, but got %s", version)
+		}
+	}
+
+	if _, err = db.Exec("SET NAMES utf8mb4"); err != nil {
+		t.Errorf("error '%s' was not expected for ignored exec", err)
+	}
+
+	stmt, err := db.Prepare("SET SESSION sql_mode = 'ANSI'")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected for ignored prepare", err)
+	}
+	if _, err = stmt.Exec(); err != nil {
+		t.Errorf("error '%s' was not expected for ignored prepared exec", err)
+	}
+
+	if _, err = db.Exec("UPDATE users SET active = 1"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
@@ -0,0 +1,113 @@
+package sqlmock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StatementKind classifies sql statements by what they do
+type StatementKind int
+
+// kinds of sql statements
+const (
+	StatementOther StatementKind = iota
+	StatementSelect
+	StatementInsert
+	StatementUpdate
+	StatementDelete
+	StatementDDL
+)
+
+var statementKindNames = [...]string{"other", "SELECT", "INSERT", "UPDATE", "DELETE", "DDL"}
+
+func (k StatementKind) String() string {
+	if k >= 0 && int(k) < len(statementKindNames) {
+		return statementKindNames[k]
+	}
+	return "StatementKind(unknown)"
+}
+
+// keywords which statements of a kind begin with
+var statementKeywords = map[StatementKind][]string{
+	StatementSelect: {"SELECT"},
+	StatementInsert: {"INSERT", "REPLACE"},
+	StatementUpdate: {"UPDATE"},
+	StatementDelete: {"DELETE"},
+	StatementDDL:    {"CREATE", "ALTER", "DROP", "TRUNCATE", "RENAME", "COMMENT"},
+}
+
+// ClassifyStatement returns the kind of the sql statement by its
+// leading keyword
+func ClassifyStatement(query string) StatementKind {
+	query = strings.TrimLeft(query, " \t\n\r(")
+	for kind := StatementSelect; kind <= StatementDDL; kind++ {
+		for _, keyword := range statementKeywords[kind] {
+			if hasPrefixFold(query, keyword) && (len(query) == len(keyword) || !isWordByte(query[len(keyword)])) {
+				return kind
+			}
+		}
+	}
+	return StatementOther
+}
+
+// Kind returns a sql regexp, which matches statements of the kind,
+// so that a statement could be expected by what it does, instead of
+// by its text:
+//
+//	mock.ExpectExec(sqlmock.Kind(sqlmock.StatementDDL)).WillReturnResult(sqlmock.NewResult(0, 0))
+//
+// A regexp of StatementOther kind matches any statement.
+func Kind(kind StatementKind) string {
+	keywords := statementKeywords[kind]
+	if len(keywords) == 0 {
+		return ""
+	}
+	return `(?i)^\(*\s*(?:` + strings.Join(keywords, "|") + `)\b`
+}
+
+// count keeps the number of calls, which matched an expectation,
+// by the statement kind of the stripped query
+func (c *sqlmock) count(query string) {
+	kind := ClassifyStatement(query)
+	c.Lock()
+	defer c.Unlock()
+
+	if c.statements == nil {
+		c.statements = make(map[StatementKind]int)
+	}
+	c.statements[kind]++
+}
+
+func (c *sqlmock) StatementCounts() map[StatementKind]int {
+	c.Lock()
+	defer c.Unlock()
+
+	counts := make(map[StatementKind]int, len(c.statements))
+	for kind, n := range c.statements {
+		counts[kind] = n
+	}
+	return counts
+}
+
+func (c *sqlmock) StatementsWere(expected map[StatementKind]int) error {
+	counts := c.StatementCounts()
+
+	kinds := make([]int, 0, len(expected))
+	for kind := range expected {
+		kinds = append(kinds, int(kind))
+	}
+	sort.Ints(kinds)
+
+	var problems []string
+	for _, k := range kinds {
+		kind := StatementKind(k)
+		if counts[kind] != expected[kind] {
+			problems = append(problems, fmt.Sprintf("%d %s statements were expected, but %d were made", expected[kind], kind, counts[kind]))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("statements do not match: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package sqlmock
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestClassifyStatement(t *testing.T) {
+	cases := map[string]StatementKind{
+		"SELECT id FROM users":                 StatementSelect,
+		"(SELECT 1) UNION (SELECT 2)":          StatementSelect,
+		"insert into users(name) values(?)":    StatementInsert,
+		"REPLACE INTO users(name) VALUES(?)":   StatementInsert,
+		"UPDATE users SET name = ?":            StatementUpdate,
+		"DELETE FROM users":                    StatementDelete,
+		"CREATE TABLE users (id INT)":          StatementDDL,
+		"ALTER TABLE users ADD COLUMN age INT": StatementDDL,
+		"TRUNCATE users":                       StatementDDL,
+		"SHOW TABLES":                          StatementOther,
+		"UPDATED_AT":                           StatementOther,
+	}
+	for query, kind := range cases {
+		if actual := ClassifyStatement(query); actual != kind {
+			t.Errorf("expected query '%s' to be classified as %s, but got %s", query, kind, actual)
+		}
+		if kind != StatementOther && !regexp.MustCompile(Kind(kind)).MatchString(query) {
+			t.Errorf("expected query '%s' to be matched by regexp of %s kind", query, kind)
+		}
+	}
+}
+
+func TestStatementsWere(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(Kind(StatementDDL)).WillReturnResult(NewResult(0, 0))
+	mock.ExpectExec(Kind(StatementUpdate)).WillReturnResult(NewResult(0, 1)).Times(2)
+
+	if _, err := db.Exec("CREATE INDEX users_name ON users(name)"); err != nil {
+		t.Errorf("error '%s' was not expected while creating an index", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := db.Exec("UPDATE users SET name = ?", "bob"); err != nil {
+			t.Errorf("error '%s' was not expected while updating", err)
+		}
+	}
+
+	if err := mock.StatementsWere(map[StatementKind]int{StatementUpdate: 2, StatementDDL: 1, StatementDelete: 0}); err != nil {
+		t.Errorf("error '%s' was not expected, statements are as expected", err)
+	}
+	err = mock.StatementsWere(map[StatementKind]int{StatementUpdate: 1, StatementDDL: 0})
+	if err == nil || err.Error() != "statements do not match: 1 UPDATE statements were expected, but 2 were made, 0 DDL statements were expected, but 1 were made" {
+		t.Errorf("expected an error about the statement counts, but got: %v", err)
+	}
+}
+
+func TestStatementsWereCountsOnlyExpectedCalls(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.IgnoreQueries("^SELECT VERSION\\(\\)").
+		WillReturnRows(NewRows([]string{"version"}).AddRow("8.0.32"))
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	var version string
+	if err = db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		t.Errorf("error '%s' was not expected while querying version", err)
+	}
+	if _, err = db.Exec("DELETE FROM users"); err != nil {
+		t.Errorf("error '%s' was not expected, since expectations are not required", err)
+	}
+	if _, err = db.Exec("UPDATE users SET name = ?", "bob"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+
+	if err := mock.StatementsWere(map[StatementKind]int{StatementSelect: 0, StatementUpdate: 1, StatementDelete: 0}); err != nil {
+		t.Errorf("error '%s' was not expected, ignored and tolerated calls should not be counted", err)
+	}
+}
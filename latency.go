@@ -0,0 +1,167 @@
+package sqlmock
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyProfile is a weighted distribution of delays, which
+// are drawn from a seeded random source, so that tail latency
+// handling could be tested statistically, yet reproducibly.
+// Created by NewLatencyProfile.
+type LatencyProfile struct {
+	sync.Mutex
+	rnd     *rand.Rand
+	weights []float64
+	delays  []time.Duration
+	total   float64
+}
+
+// NewLatencyProfile creates an empty latency profile, which
+// draws delays using the given seed. Delays are added by Add.
+func NewLatencyProfile(seed int64) *LatencyProfile {
+	return &LatencyProfile{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Add makes the delay to be drawn with the given relative weight,
+// for example 95% of calls could be delayed by a millisecond and
+// 5% by 200 milliseconds:
+//
+//	NewLatencyProfile(1).Add(95, time.Millisecond).Add(5, 200*time.Millisecond)
+func (p *LatencyProfile) Add(weight float64, delay time.Duration) *LatencyProfile {
+	p.Lock()
+	p.weights = append(p.weights, weight)
+	p.delays = append(p.delays, delay)
+	p.total += weight
+	p.Unlock()
+	return p
+}
+
+// Next draws the next delay, it is zero if profile is empty
+func (p *LatencyProfile) Next() time.Duration {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.total <= 0 {
+		return 0
+	}
+
+	n := p.rnd.Float64() * p.total
+	for i, w := range p.weights {
+		if n < w {
+			return p.delays[i]
+		}
+		n -= w
+	}
+	return p.delays[len(p.delays)-1]
+}
+
+func (c *sqlmock) UseLatencyProfile(profile *LatencyProfile) {
+	c.Lock()
+	c.latency = profile
+	c.Unlock()
+}
+
+// latencyProfile returns the profile used for all expectations
+func (c *sqlmock) latencyProfile() *LatencyProfile {
+	c.Lock()
+	defer c.Unlock()
+	return c.latency
+}
+
+// LatencySummary summarizes durations of Query() and Exec() calls,
+// including simulated delays, of queries sharing a fingerprint
+type LatencySummary struct {
+	// Fingerprint is the stripped query, which literals and
+	// numbered placeholders are replaced with ? and lists of
+	// placeholders with (...)
+	Fingerprint string
+
+	Calls         int
+	Total         time.Duration
+	Min, Max      time.Duration
+	P50, P90, P99 time.Duration
+
+	// Durations of all the calls in ascending order
+	Durations []time.Duration
+}
+
+// SlowerThan counts calls, which took longer than d
+func (s LatencySummary) SlowerThan(d time.Duration) int {
+	return len(s.Durations) - sort.Search(len(s.Durations), func(i int) bool {
+		return s.Durations[i] > d
+	})
+}
+
+// percentile returns the duration, which p percent of calls did not exceed
+func (s LatencySummary) percentile(p int) time.Duration {
+	i := (len(s.Durations)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return s.Durations[i]
+}
+
+func (c *sqlmock) RecordLatencies() {
+	c.Lock()
+	c.timing = true
+	c.Unlock()
+}
+
+// observe records the duration of a call of the stripped query started
+// at, if latencies are recorded
+func (c *sqlmock) observe(query string, started time.Time) {
+	d := time.Since(started)
+	c.Lock()
+	defer c.Unlock()
+	if !c.timing {
+		return
+	}
+	if c.latencies == nil {
+		c.latencies = make(map[string][]time.Duration)
+	}
+	c.latencies[query] = append(c.latencies[query], d)
+}
+
+func (c *sqlmock) Latencies() []LatencySummary {
+	c.Lock()
+	byFingerprint := make(map[string][]time.Duration)
+	for query, durations := range c.latencies {
+		fp := fingerprint(query)
+		byFingerprint[fp] = append(byFingerprint[fp], durations...)
+	}
+	c.Unlock()
+
+	summaries := make([]LatencySummary, 0, len(byFingerprint))
+	for fp, durations := range byFingerprint {
+		sort.Sort(durationSlice(durations))
+		s := LatencySummary{
+			Fingerprint: fp,
+			Calls:       len(durations),
+			Min:         durations[0],
+			Max:         durations[len(durations)-1],
+			Durations:   durations,
+		}
+		for _, d := range durations {
+			s.Total += d
+		}
+		s.P50, s.P90, s.P99 = s.percentile(50), s.percentile(90), s.percentile(99)
+		summaries = append(summaries, s)
+	}
+	sort.Sort(byFingerprintSlice(summaries))
+	return summaries
+}
+
+type durationSlice []time.Duration
+
+func (s durationSlice) Len() int           { return len(s) }
+func (s durationSlice) Less(i, j int) bool { return s[i] < s[j] }
+func (s durationSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type byFingerprintSlice []LatencySummary
+
+func (s byFingerprintSlice) Len() int           { return len(s) }
+func (s byFingerprintSlice) Less(i, j int) bool { return s[i].Fingerprint < s[j].Fingerprint }
+func (s byFingerprintSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
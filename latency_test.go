@@ -0,0 +1,163 @@
+package sqlmock
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLatencyProfileIsReproducible(t *testing.T) {
+	first := NewLatencyProfile(42).Add(95, time.Millisecond).Add(5, 200*time.Millisecond)
+	second := NewLatencyProfile(42).Add(95, time.Millisecond).Add(5, 200*time.Millisecond)
+
+	var slow int
+	for i := 0; i < 1000; i++ {
+		d := first.Next()
+		if d != second.Next() {
+			t.Fatalf("expected profiles with the same seed to draw the same delays, at draw %d", i)
+		}
+		if d == 200*time.Millisecond {
+			slow++
+		}
+	}
+
+	if slow < 20 || slow > 80 {
+		t.Errorf("expected about 5%% of 1000 delays to be slow, but got %d", slow)
+	}
+
+	if d := NewLatencyProfile(1).Next(); d != 0 {
+		t.Errorf("expected empty profile to draw no delay, but got %s", d)
+	}
+}
+
+func TestQueryDelay(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.UseLatencyProfile(NewLatencyProfile(1).Add(1, 30*time.Millisecond))
+	mock.ExpectExec("DELETE FROM sessions").WillReturnResult(NewResult(0, 0))
+	mock.ExpectQuery("SELECT (.+) FROM users").
+		WillDelayBy(NewLatencyProfile(1).Add(1, 10*time.Millisecond)).
+		WillReturnRows(NewRows([]string{"id"}))
+
+	start := time.Now()
+	if _, err = db.Exec("DELETE FROM sessions"); err != nil {
+		t.Errorf("error '%s' was not expected while deleting sessions", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected exec to be delayed by the mock latency profile, but it took %s", elapsed)
+	}
+
+	start = time.Now()
+	rows, err := db.Query("SELECT id FROM users")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while querying users", err)
+	} else {
+		rows.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("expected query to be delayed by its own latency profile, but it took %s", elapsed)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestPeakInFlight(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	if n := mock.PeakInFlight(); n != 0 {
+		t.Errorf("expected no calls to be in flight, but got %d", n)
+	}
+
+	mock.ExpectExec("UPDATE users").WillDelayFor(50 * time.Millisecond).WillReturnResult(NewResult(0, 1)).Reusable()
+	db.SetMaxOpenConns(3)
+
+	done := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		go func() {
+			if _, err := db.Exec("UPDATE users SET visits = visits + 1"); err != nil {
+				t.Errorf("error '%s' was not expected while updating users", err)
+			}
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		<-done
+	}
+
+	if n := mock.PeakInFlight(); n != 3 {
+		t.Errorf("expected connection pool to limit calls in flight to 3, but got %d", n)
+	}
+}
+
+func TestLatencies(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RecordLatencies()
+	mock.ExpectQuery("SELECT name FROM users").WillDelayFor(20 * time.Millisecond).WillReturnRows(NewRows([]string{"name"})).Times(2)
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(NewRows([]string{"name"})).Reusable()
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 3))
+
+	for _, id := range []int{1, 2, 3, 4} {
+		rs, err := db.Query(fmt.Sprintf("SELECT name FROM users WHERE id = %d", id))
+		if err != nil {
+			t.Fatalf("error '%s' was not expected while selecting a user", err)
+		}
+		rs.Close()
+	}
+	if _, err := db.Exec("DELETE FROM users WHERE id IN (?, ?, ?)", 1, 2, 3); err != nil {
+		t.Fatalf("error '%s' was not expected while deleting users", err)
+	}
+
+	summaries := mock.Latencies()
+	if len(summaries) != 2 {
+		t.Fatalf("expected latencies of 2 fingerprints, but got: %+v", summaries)
+	}
+	if fp := summaries[0].Fingerprint; fp != "DELETE FROM users WHERE id IN (...)" {
+		t.Errorf("unexpected fingerprint: %s", fp)
+	}
+
+	selects := summaries[1]
+	if selects.Fingerprint != "SELECT name FROM users WHERE id = ?" || selects.Calls != 4 {
+		t.Fatalf("expected 4 calls of the select fingerprint, but got: %+v", selects)
+	}
+	if n := selects.SlowerThan(10 * time.Millisecond); n != 2 {
+		t.Errorf("expected 2 slow calls, but got %d", n)
+	}
+	if selects.Max < 20*time.Millisecond || selects.P50 >= 20*time.Millisecond {
+		t.Errorf("unexpected latency distribution: %+v", selects)
+	}
+}
+
+func TestLatenciesAreNotRecordedByDefault(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+	if _, err := db.Exec("DELETE FROM users WHERE id = ?", 1); err != nil {
+		t.Fatalf("error '%s' was not expected while deleting a user", err)
+	}
+	if summaries := mock.Latencies(); len(summaries) != 0 {
+		t.Errorf("expected no latencies without RecordLatencies, but got: %+v", summaries)
+	}
+}
@@ -0,0 +1,88 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+func (c *sqlmock) ConnMaxLifetime(d time.Duration) {
+	c.Lock()
+	c.connLifetime = d
+	c.Unlock()
+}
+
+func (c *sqlmock) ExpectConnect() *ExpectedConnect {
+	e := &ExpectedConnect{}
+	c.expect(e)
+	return e
+}
+
+// expire fails the call with driver.ErrBadConn, once the connection
+// outlived its max lifetime on the simulated clock. So that
+// database/sql closes it and retries the call on a new connection.
+// Connections in transaction are never expired.
+func (c *sqlmock) expire() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.connLifetime <= 0 || c.inTx || c.clock.Now().Sub(c.connectedAt) < c.connLifetime {
+		return nil
+	}
+	c.expiring++
+	return driver.ErrBadConn
+}
+
+// closeExpired tells whether the connection being closed has expired,
+// so that the mock stays registered to be reconnected
+func (c *sqlmock) closeExpired() bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.expiring == 0 {
+		return false
+	}
+	c.expiring--
+	c.reconnects++
+	return true
+}
+
+// connect is called for every opened connection, reconnects after the
+// connection expired must be expected by ExpectConnect. It must be
+// called under the driver lock.
+func (c *sqlmock) connect(handle string) error {
+	c.Lock()
+	c.connectedAt = c.clock.Now()
+	reconnect := c.reconnects > 0
+	if reconnect {
+		c.reconnects--
+	}
+	c.Unlock()
+
+	if !reconnect {
+		return nil
+	}
+
+	matched, next, exhausted := c.matchExpectation(func(e expectation) bool {
+		_, ok := e.(*ExpectedConnect)
+		return ok
+	}, nil)
+	if next != nil {
+		return failf(handle, "call to database Connect, was not expected, next expectation is: %s", lockedString(next))
+	}
+
+	expected, _ := matched.(*ExpectedConnect)
+	if expected == nil {
+		if c.requireExpectations {
+			msg := "call to database Connect was not expected"
+			if exhausted {
+				msg = "all expectations were already fulfilled, " + msg
+			}
+			return failf(handle, msg)
+		}
+		return nil
+	}
+
+	defer expected.Unlock()
+	expected.trigger()
+	return expected.err
+}
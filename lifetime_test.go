@@ -0,0 +1,42 @@
+package sqlmock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConnMaxLifetime(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	db.SetMaxOpenConns(1)
+	mock.MatchExpectationsInOrder(true)
+	mock.ConnMaxLifetime(time.Hour)
+
+	mock.ExpectExec("CREATE TEMPORARY TABLE batch").WillReturnResult(NewResult(0, 0))
+	mock.ExpectClose()
+	mock.ExpectConnect()
+	mock.ExpectExec("CREATE TEMPORARY TABLE batch").WillReturnResult(NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO batch").WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("CREATE TEMPORARY TABLE batch (id INT)"); err != nil {
+		t.Fatalf("error '%s' was not expected while creating a table", err)
+	}
+
+	mock.Clock().Advance(time.Hour)
+	// the connection expires, the temporary table has to be created again
+	if _, err := db.Exec("CREATE TEMPORARY TABLE batch (id INT)"); err != nil {
+		t.Fatalf("error '%s' was not expected while creating a table on a new connection", err)
+	}
+	if _, err := db.Exec("INSERT INTO batch (id) VALUES (1)"); err != nil {
+		t.Fatalf("error '%s' was not expected while inserting into a table", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
@@ -0,0 +1,69 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+const (
+	// PostgresMaxParams is the number of parameters postgres
+	// allows to bind to a single statement
+	PostgresMaxParams = 65535
+
+	// MySQLMaxAllowedPacket is the default max_allowed_packet
+	// of MySQL 8.0 server, in bytes
+	MySQLMaxAllowedPacket = 64 << 20
+)
+
+func (c *sqlmock) LimitParams(max int) {
+	c.Lock()
+	c.maxParams = max
+	c.Unlock()
+}
+
+func (c *sqlmock) LimitPacketSize(max int) {
+	c.Lock()
+	c.maxPacket = max
+	c.Unlock()
+}
+
+// checkLimits verifies that the query and its args fit the
+// limits of bound parameters and packet size, if any were set
+func (c *sqlmock) checkLimits(query string, args []driver.Value) error {
+	c.Lock()
+	maxParams, maxPacket := c.maxParams, c.maxPacket
+	c.Unlock()
+
+	if maxParams > 0 && len(args) > maxParams {
+		return fmt.Errorf("extended protocol limited to %d parameters, but got %d", maxParams, len(args))
+	}
+	if maxPacket > 0 {
+		if size := packetSize(query, args); size > maxPacket {
+			return fmt.Errorf("Error 1153: Got a packet bigger than 'max_allowed_packet' bytes (%d > %d)", size, maxPacket)
+		}
+	}
+	return nil
+}
+
+// packetSize estimates how many bytes the query
+// and its args take, when sent to the server
+func packetSize(query string, args []driver.Value) int {
+	size := len(query)
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		case bool:
+			size++
+		case time.Time:
+			size += 12
+		case nil:
+		default:
+			size += 8
+		}
+	}
+	return size
+}
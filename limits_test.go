@@ -0,0 +1,52 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLimitParams(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.LimitParams(4)
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(NewResult(2, 2))
+
+	_, err = db.Exec("INSERT INTO users (id, name) VALUES (?, ?), (?, ?), (?, ?)", 1, "a", 2, "b", 3, "c")
+	if err == nil || err.Error() != "extended protocol limited to 4 parameters, but got 6" {
+		t.Errorf("expected an error about too many parameters, but got: %v", err)
+	}
+
+	if _, err := db.Exec("INSERT INTO users (id, name) VALUES (?, ?), (?, ?)", 1, "a", 2, "b"); err != nil {
+		t.Errorf("error '%s' was not expected, since the batch fits the limit", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestLimitPacketSize(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.LimitPacketSize(64)
+	mock.ExpectExec("INSERT INTO blobs").WillReturnResult(NewResult(1, 1))
+
+	_, err = db.Exec("INSERT INTO blobs (data) VALUES (?)", strings.Repeat("x", 64))
+	if err == nil || !strings.HasPrefix(err.Error(), "Error 1153: Got a packet bigger than 'max_allowed_packet' bytes") {
+		t.Errorf("expected an error about the packet size, but got: %v", err)
+	}
+
+	if _, err := db.Exec("INSERT INTO blobs (data) VALUES (?)", []byte("small")); err != nil {
+		t.Errorf("error '%s' was not expected, since the packet fits the limit", err)
+	}
+}
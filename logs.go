@@ -0,0 +1,333 @@
+package sqlmock
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// postgres csvlog columns, which are used to read statements
+const (
+	pgLogSeverity = 11
+	pgLogMessage  = 13
+	pgLogDetail   = 14
+)
+
+var (
+	pgStatementRe  = regexp.MustCompile(`^(?:duration: [\d.]+ ms\s+)?(?:statement|execute [^:]+): (?s)(.+)$`)
+	pgParameterRe  = regexp.MustCompile(`\$\d+ = (NULL|'(?:[^']|'')*')`)
+	mysqlLogLineRe = regexp.MustCompile(`^(?:\S+(?: +\S+)?)?\t\t? *(\d+) (\w+(?: \w+)?)\t?(.*)$`)
+	mysqlNumberRe  = regexp.MustCompile(`^-?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?`)
+)
+
+// loggedArg matches an argument by the text representation,
+// which was written to the server query log
+type loggedArg struct {
+	value string
+	null  bool
+}
+
+func (a loggedArg) Match(v driver.Value) bool {
+	if v == nil || a.null {
+		return v == nil && a.null
+	}
+	if b, ok := v.([]byte); ok {
+		return string(b) == a.value
+	}
+	return fmt.Sprintf("%v", v) == a.value
+}
+
+func (a loggedArg) String() string {
+	if a.null {
+		return "NULL"
+	}
+	return "'" + a.value + "'"
+}
+
+// ExpectPostgresCSVLog reads the postgres server log written in csvlog
+// format and queues an expectation on mock for every logged statement,
+// in the same order, which the mock is set to match expectations in.
+// Bound parameters are taken from the log entry details, when logged,
+// and are matched by their text representation.
+//
+// Begin, Commit and Rollback statements are expected as transaction
+// actions, statements returning rows as queries which return no rows
+// and all other statements as exec, returning an empty result.
+func ExpectPostgresCSVLog(mock SqlmockCommon, r io.Reader) error {
+	mock.MatchExpectationsInOrder(true)
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read postgres csvlog: %s", err)
+		}
+
+		if len(record) <= pgLogDetail || record[pgLogSeverity] != "LOG" {
+			continue
+		}
+
+		m := pgStatementRe.FindStringSubmatch(record[pgLogMessage])
+		if m == nil {
+			continue
+		}
+
+		var args []driver.Value
+		if detail := record[pgLogDetail]; strings.HasPrefix(detail, "parameters: ") {
+			for _, p := range pgParameterRe.FindAllStringSubmatch(detail, -1) {
+				if p[1] == "NULL" {
+					args = append(args, loggedArg{null: true})
+					continue
+				}
+				value := strings.Replace(p[1][1:len(p[1])-1], "''", "'", -1)
+				args = append(args, loggedArg{value: value})
+			}
+		}
+		expectLogged(mock, m[1], args)
+	}
+}
+
+// ExpectMySQLGeneralLog reads the mysql general query log and queues an
+// expectation on mock for every logged Query or Execute command, in the
+// same order, which the mock is set to match expectations in.
+//
+// MySQL logs an executed prepared statement with its arguments already
+// interpolated, so they are recovered by aligning the Execute command to
+// the statement prepared by the Prepare command of the same connection.
+// The statement is expected with its ? placeholders and the arguments,
+// which are matched by their text representation. Statements of Query
+// commands are expected without arguments, as they were logged, which
+// includes the ones interpolated by the client, like the mysql driver
+// does with interpolateParams=true.
+//
+// Statements are expected the same way as by ExpectPostgresCSVLog.
+func ExpectMySQLGeneralLog(mock SqlmockCommon, r io.Reader) error {
+	mock.MatchExpectationsInOrder(true)
+
+	prepared := make(map[string][]string) // statements by connection id
+	var id, command, query string
+	flush := func() {
+		switch command {
+		case "Prepare":
+			prepared[id] = append(prepared[id], stripQuery(query))
+		case "Execute":
+			expectExecuted(mock, prepared[id], query)
+		case "Query":
+			expectLogged(mock, query, nil)
+		case "Quit":
+			delete(prepared, id)
+		}
+		id, command, query = "", "", ""
+	}
+
+	// lines are read whole, since logged statements may be long
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read mysql general log: %s", err)
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if m := mysqlLogLineRe.FindStringSubmatch(line); m != nil {
+			flush()
+			id, command, query = m[1], m[2], m[3]
+			continue
+		}
+
+		if strings.Contains(line, "started with:") {
+			flush() // server restart header
+			continue
+		}
+
+		if command != "" {
+			query += "\n" + line // multiline statement
+		}
+	}
+	flush()
+	return nil
+}
+
+// expectLogged queues an expectation for a statement read from server log
+func expectLogged(mock SqlmockCommon, query string, args []driver.Value) {
+	query = stripQuery(query)
+	sqlRegexStr := "^" + regexp.QuoteMeta(query) + "$"
+
+	switch statement := strings.ToUpper(strings.TrimRight(query, "; ")); {
+	case statement == "BEGIN" || strings.HasPrefix(statement, "START TRANSACTION"):
+		mock.ExpectBegin()
+	case statement == "COMMIT":
+		mock.ExpectCommit()
+	case statement == "ROLLBACK":
+		mock.ExpectRollback()
+	case returnsRows(statement):
+		mock.ExpectQuery(sqlRegexStr).WithArgs(args...).WillReturnRows(NewRows(nil))
+	default:
+		mock.ExpectExec(sqlRegexStr).WithArgs(args...).WillReturnResult(NewResult(0, 0))
+	}
+}
+
+// expectExecuted queues an expectation for a prepared statement, which
+// was logged executed with interpolated arguments, the statements prepared
+// by the connection are tried to be aligned with it, the latest first
+func expectExecuted(mock SqlmockCommon, prepared []string, executed string) {
+	executed = strings.TrimSpace(executed)
+	for i := len(prepared) - 1; i >= 0; i-- {
+		if args, ok := executedArgs(prepared[i], executed); ok {
+			expectLogged(mock, prepared[i], args)
+			return
+		}
+	}
+	expectLogged(mock, executed, nil) // prepared before the log started
+}
+
+// executedArgs aligns the executed statement with the prepared one and
+// returns the values, which were logged in place of its placeholders
+func executedArgs(prepared, executed string) ([]driver.Value, bool) {
+	parts := splitPlaceholders(prepared)
+	rest, ok := consumeLogged(executed, parts[0])
+	if !ok {
+		return nil, false
+	}
+
+	var args []driver.Value
+	for _, part := range parts[1:] {
+		arg, n, ok := loggedValue(rest)
+		if !ok {
+			return nil, false
+		}
+		if rest, ok = consumeLogged(rest[n:], part); !ok {
+			return nil, false
+		}
+		args = append(args, arg)
+	}
+	return args, rest == ""
+}
+
+// splitPlaceholders splits the prepared statement at its ? placeholders,
+// which are not quoted
+func splitPlaceholders(query string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			parts = append(parts, query[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, query[start:])
+}
+
+// consumeLogged returns the rest of the logged statement after the text,
+// which must begin it, whitespace differences aside, since the statement
+// is logged formatted the way the client sent it
+func consumeLogged(logged, text string) (string, bool) {
+	for text != "" {
+		if isSpace(text[0]) {
+			text = strings.TrimLeft(text, " \t\r\n")
+			if logged == "" || !isSpace(logged[0]) {
+				return "", false
+			}
+			logged = strings.TrimLeft(logged, " \t\r\n")
+			continue
+		}
+		if logged == "" || logged[0] != text[0] {
+			return "", false
+		}
+		logged, text = logged[1:], text[1:]
+	}
+	return strings.TrimLeft(logged, " \t\r\n"), true
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// loggedValue reads a value, which mysql logged in place of a placeholder,
+// a quoted string, optionally with a charset like _binary'...', a number
+// or NULL, and returns the number of bytes it takes
+func loggedValue(s string) (loggedArg, int, bool) {
+	if len(s) >= 4 && strings.EqualFold(s[:4], "NULL") {
+		return loggedArg{null: true}, 4, true
+	}
+	if n := mysqlNumberRe.FindStringIndex(s); n != nil {
+		return loggedArg{value: s[:n[1]]}, n[1], true
+	}
+
+	i := 0
+	if strings.HasPrefix(s, "_") {
+		// a charset introducer, which is a single word
+		if i = strings.IndexByte(s, '\''); i < 0 || strings.ContainsAny(s[:i], " ,)") {
+			return loggedArg{}, 0, false
+		}
+	}
+	if i >= len(s) || s[i] != '\'' {
+		return loggedArg{}, 0, false
+	}
+
+	var value []byte
+	for i++; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			value = append(value, unescapeMySQL(s[i]))
+		case c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+			value = append(value, c)
+		case c == '\'':
+			return loggedArg{value: string(value)}, i + 1, true
+		default:
+			value = append(value, c)
+		}
+	}
+	return loggedArg{}, 0, false
+}
+
+// unescapeMySQL returns the character escaped by a backslash in a string
+// literal of mysql
+func unescapeMySQL(c byte) byte {
+	switch c {
+	case '0':
+		return 0
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'Z':
+		return 26
+	}
+	return c
+}
+
+// returnsRows guesses whether an upper cased statement returns rows
+func returnsRows(statement string) bool {
+	for _, prefix := range []string{"SELECT", "WITH", "SHOW", "VALUES", "EXPLAIN", "DESCRIBE", "DESC ", "TABLE "} {
+		if strings.HasPrefix(statement, prefix) {
+			return true
+		}
+	}
+	return strings.Contains(statement, " RETURNING ")
+}
@@ -0,0 +1,203 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpectPostgresCSVLog(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+
+	log := `2023-02-01 10:00:00.001 UTC,"app","shop",42,"[local]",63da3,1,"BEGIN",2023-02-01 10:00:00 UTC,3/7,0,LOG,00000,"statement: BEGIN",,,,,,,,,"app"
+2023-02-01 10:00:00.002 UTC,"app","shop",42,"[local]",63da3,2,"SELECT",2023-02-01 10:00:00 UTC,3/7,0,LOG,00000,"execute <unnamed>: SELECT id, name
+  FROM users WHERE id = $1 AND note = $2","parameters: $1 = '5', $2 = 'it''s'",,,,,,,,"app"
+2023-02-01 10:00:00.003 UTC,"app","shop",42,"[local]",63da3,3,"UPDATE",2023-02-01 10:00:00 UTC,3/7,0,LOG,00000,"duration: 0.112 ms  execute <unnamed>: UPDATE users SET note = $1 WHERE id = $2","parameters: $1 = NULL, $2 = '5'",,,,,,,,"app"
+2023-02-01 10:00:00.004 UTC,"app","shop",42,"[local]",63da3,4,"idle",2023-02-01 10:00:00 UTC,3/7,0,LOG,00000,"disconnection: session time: 0:00:00.003",,,,,,,,,"app"
+2023-02-01 10:00:00.005 UTC,"app","shop",42,"[local]",63da3,5,"COMMIT",2023-02-01 10:00:00 UTC,3/7,0,LOG,00000,"statement: COMMIT",,,,,,,,,"app"
+`
+	if err := ExpectPostgresCSVLog(mock, strings.NewReader(log)); err != nil {
+		t.Fatalf("an error '%s' was not expected while reading postgres csvlog", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	rows, err := tx.Query("SELECT id, name FROM users WHERE id = $1 AND note = $2", 5, "it's")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while querying logged statement", err)
+	} else {
+		rows.Close()
+	}
+
+	if _, err = tx.Exec("UPDATE users SET note = $1 WHERE id = $2", nil, []byte("5")); err != nil {
+		t.Errorf("error '%s' was not expected while executing logged statement", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while commiting a transaction", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestExpectPostgresCSVLogArgumentsMismatch(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	log := `2023-02-01 10:00:00.002 UTC,"app","shop",42,"[local]",63da3,2,"DELETE",2023-02-01 10:00:00 UTC,3/7,0,LOG,00000,"execute S_1: DELETE FROM users WHERE id = $1","parameters: $1 = '5'",,,,,,,,"app"
+`
+	if err := ExpectPostgresCSVLog(mock, strings.NewReader(log)); err != nil {
+		t.Fatalf("an error '%s' was not expected while reading postgres csvlog", err)
+	}
+
+	mock.RequireExpectations(true)
+	if _, err = db.Exec("DELETE FROM users WHERE id = $1", 6); err == nil {
+		t.Error("an error was expected since argument differs from logged parameter")
+	}
+}
+
+func TestExpectMySQLGeneralLog(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+
+	log := "/usr/sbin/mysqld, Version: 8.0.32 (MySQL Community Server - GPL). started with:\n" +
+		"Tcp port: 3306  Unix socket: /var/run/mysqld/mysqld.sock\n" +
+		"Time                 Id Command    Argument\n" +
+		"2023-02-01T10:00:00.000001Z\t    8 Connect\troot@localhost on shop using TCP/IP\n" +
+		"2023-02-01T10:00:00.000002Z\t    8 Query\tSTART TRANSACTION\n" +
+		"2023-02-01T10:00:00.000003Z\t    8 Prepare\tSELECT name FROM users WHERE id = ?\n" +
+		"2023-02-01T10:00:00.000004Z\t    8 Execute\tSELECT name\n" +
+		"  FROM users WHERE id = 5\n" +
+		"2023-02-01T10:00:00.000005Z\t    8 Close stmt\t\n" +
+		"2023-02-01T10:00:00.000006Z\t    8 Query\tINSERT INTO visits (user_id) VALUES (5)\n" +
+		"2023-02-01T10:00:00.000007Z\t    8 Query\tROLLBACK\n" +
+		"2023-02-01T10:00:00.000008Z\t    8 Quit\t\n"
+
+	if err := ExpectMySQLGeneralLog(mock, strings.NewReader(log)); err != nil {
+		t.Fatalf("an error '%s' was not expected while reading mysql general log", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	rows, err := tx.Query("SELECT name FROM users WHERE id = ?", 5)
+	if err != nil {
+		t.Errorf("error '%s' was not expected while querying logged statement", err)
+	} else {
+		rows.Close()
+	}
+
+	if _, err = tx.Exec("INSERT INTO visits (user_id) VALUES (5)"); err != nil {
+		t.Errorf("error '%s' was not expected while executing logged statement", err)
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Errorf("error '%s' was not expected while rolling back a transaction", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestExpectMySQLGeneralLogPreparedStatements(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	log := "Time                 Id Command    Argument\n" +
+		"2023-02-01T10:00:00.000001Z\t    8 Prepare\tUPDATE users SET name = ?, note = ? WHERE id = ? AND flag = '?'\n" +
+		"2023-02-01T10:00:00.000002Z\t    9 Prepare\tSELECT id FROM users WHERE name = ?\n" +
+		"2023-02-01T10:00:00.000003Z\t    8 Execute\tUPDATE users SET name = 'O\\'Brien', note = NULL WHERE id = 5 AND flag = '?'\n" +
+		"2023-02-01T10:00:00.000004Z\t    9 Execute\tSELECT id FROM users WHERE name = _utf8mb4'bob'\n" +
+		"2023-02-01T10:00:00.000005Z\t    8 Close stmt\t\n"
+
+	if err := ExpectMySQLGeneralLog(mock, strings.NewReader(log)); err != nil {
+		t.Fatalf("an error '%s' was not expected while reading mysql general log", err)
+	}
+
+	if _, err := db.Query("SELECT id FROM users WHERE name = ?", "bob"); err == nil {
+		t.Error("an error was expected, since the statements are logged in other order")
+	}
+	if _, err := db.Exec("UPDATE users SET name = ?, note = ? WHERE id = ? AND flag = '?'", "O'Brien", nil, 5); err != nil {
+		t.Errorf("error '%s' was not expected while executing logged statement", err)
+	}
+	rows, err := db.Query("SELECT id FROM users WHERE name = ?", "bob")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while querying logged statement", err)
+	} else {
+		rows.Close()
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestExpectMySQL5GeneralLog(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+
+	// mysql 5.x logs the time only once per second
+	log := "/usr/sbin/mysqld, Version: 5.7.41-log (MySQL Community Server (GPL)). started with:\n" +
+		"Tcp port: 3306  Unix socket: /var/run/mysqld/mysqld.sock\n" +
+		"Time                 Id Command    Argument\n" +
+		"230201 10:00:00\t    1 Connect\troot@localhost on shop\n" +
+		"\t\t    1 Query\tSELECT 1\n" +
+		"\t\t    1 Query\tSELECT 2\n" +
+		"230201  9:00:01\t    1 Query\tUPDATE users\n" +
+		"\tSET visits = visits + 1\n" +
+		"\t\t    1 Quit\t\n"
+
+	if err := ExpectMySQLGeneralLog(mock, strings.NewReader(log)); err != nil {
+		t.Fatalf("an error '%s' was not expected while reading mysql general log", err)
+	}
+
+	for _, query := range []string{"SELECT 1", "SELECT 2"} {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Errorf("error '%s' was not expected while querying logged statement", err)
+			continue
+		}
+		rows.Close()
+	}
+	if _, err = db.Exec("UPDATE users SET visits = visits + 1"); err != nil {
+		t.Errorf("error '%s' was not expected while executing logged statement", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
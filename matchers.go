@@ -0,0 +1,93 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"time"
+)
+
+// anyArg is a ready made matcher of opaque arguments
+type anyArg struct {
+	name  string
+	match func(driver.Value) bool
+}
+
+func (a anyArg) Match(v driver.Value) bool {
+	return a.match(v)
+}
+
+func (a anyArg) String() string {
+	return a.name
+}
+
+// textOf returns the text of string and []byte arguments
+func textOf(v driver.Value) (string, bool) {
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case []byte:
+		return string(t), true
+	}
+	return "", false
+}
+
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02 15:04:05.999999999Z07:00"}
+
+// AnyTime matches any time.Time argument, or a timestamp formatted by
+// RFC 3339 or as "2006-01-02 15:04:05", as a driver may send it
+func AnyTime() Argument {
+	return anyArg{name: "AnyTime", match: func(v driver.Value) bool {
+		if _, ok := v.(time.Time); ok {
+			return true
+		}
+		s, ok := textOf(v)
+		if !ok {
+			return false
+		}
+		for _, layout := range timeLayouts {
+			if _, err := time.Parse(layout, s); err == nil {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// AnyUUID matches any UUID argument in its text form, or as 16 bytes,
+// the way binary UUID columns are written
+func AnyUUID() Argument {
+	return anyArg{name: "AnyUUID", match: func(v driver.Value) bool {
+		if b, ok := v.([]byte); ok && len(b) == 16 {
+			return true
+		}
+		s, ok := textOf(v)
+		return ok && uuidRe.MatchString(s)
+	}}
+}
+
+// AnyJSON matches any string or []byte argument, which is a valid
+// JSON document
+func AnyJSON() Argument {
+	return anyArg{name: "AnyJSON", match: func(v driver.Value) bool {
+		s, ok := textOf(v)
+		if !ok {
+			return false
+		}
+		var doc interface{}
+		return json.Unmarshal([]byte(s), &doc) == nil
+	}}
+}
+
+var bcryptRe = regexp.MustCompile(`^\$2[abxy]?\$\d\d\$[./A-Za-z0-9]{53}$`)
+
+// AnyBcrypt matches any bcrypt password hash argument, like
+// $2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy
+func AnyBcrypt() Argument {
+	return anyArg{name: "AnyBcrypt", match: func(v driver.Value) bool {
+		s, ok := textOf(v)
+		return ok && bcryptRe.MatchString(s)
+	}}
+}
@@ -0,0 +1,55 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestReadyMadeMatchers(t *testing.T) {
+	cases := []struct {
+		arg     Argument
+		matches []driver.Value
+		misses  []driver.Value
+	}{
+		{AnyTime(), []driver.Value{time.Now(), "2024-03-01T10:00:00Z", []byte("2024-03-01 10:00:00.123")}, []driver.Value{"yesterday", int64(1709287200), nil}},
+		{AnyUUID(), []driver.Value{"f47ac10b-58cc-4372-a567-0e02b2c3d479", []byte("F47AC10B-58CC-4372-A567-0E02B2C3D479"), make([]byte, 16)}, []driver.Value{"f47ac10b58cc4372a5670e02b2c3d479x", "not-a-uuid", int64(1)}},
+		{AnyJSON(), []driver.Value{`{"name":"bob"}`, []byte(`[1, 2]`), "null"}, []driver.Value{`{"name":`, "", int64(1)}},
+		{AnyBcrypt(), []driver.Value{"$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"}, []driver.Value{"$2a$10$short", "secret", nil}},
+	}
+
+	for _, c := range cases {
+		for _, v := range c.matches {
+			if !c.arg.Match(v) {
+				t.Errorf("expected %s to match %+v", c.arg, v)
+			}
+		}
+		for _, v := range c.misses {
+			if c.arg.Match(v) {
+				t.Errorf("expected %s not to match %+v", c.arg, v)
+			}
+		}
+	}
+}
+
+func TestReadyMadeMatchersInExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs(AnyUUID(), AnyBcrypt(), AnyJSON(), AnyTime()).
+		WillReturnResult(NewResult(1, 1))
+
+	_, err = db.Exec("INSERT INTO users(id, password, settings, created_at) VALUES(?, ?, ?, ?)",
+		"f47ac10b-58cc-4372-a567-0e02b2c3d479", "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", `{"theme":"dark"}`, time.Now())
+	if err != nil {
+		t.Errorf("an error '%s' was not expected while inserting", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
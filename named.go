@@ -0,0 +1,96 @@
+package sqlmock
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sqlcHeaderRe matches the header, which names a query for sqlc,
+// like "-- name: GetUser :one"
+var sqlcHeaderRe = regexp.MustCompile(`(?m)^[ \t]*--[ \t]*name:[ \t]*(\S+)[ \t]+:(\S+).*$`)
+
+// namedQuery is a sql query registered by NameQueries
+type namedQuery struct {
+	command     string
+	sqlRegexStr string
+}
+
+// parseNamedQueries splits the source of sqlc annotated queries by
+// their headers, into the sql regexp of every query by its name
+func parseNamedQueries(source string) map[string]namedQuery {
+	headers := sqlcHeaderRe.FindAllStringSubmatchIndex(source, -1)
+	if len(headers) == 0 {
+		return nil
+	}
+
+	queries := make(map[string]namedQuery, len(headers))
+	for i, h := range headers {
+		end := len(source)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		body := strings.TrimSpace(source[h[1]:end])
+		body = strings.TrimSpace(strings.TrimSuffix(body, ";"))
+		queries[source[h[2]:h[3]]] = namedQuery{
+			command:     source[h[4]:h[5]],
+			sqlRegexStr: namedRegexStr(body),
+		}
+	}
+	return queries
+}
+
+// namedRegexStr returns a sql regexp, which matches the query ending
+// with the body, regardless of whitespace, so that it matches both
+// stripped and raw queries, with the sqlc header or without it
+func namedRegexStr(body string) string {
+	words := strings.Fields(body)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return `(?:^|\s)` + strings.Join(words, `\s+`) + `\s*;?\s*$`
+}
+
+func (c *sqlmock) NameQueries(sources ...string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.named == nil {
+		c.named = make(map[string]namedQuery)
+	}
+	for _, source := range sources {
+		queries := parseNamedQueries(source)
+		if len(queries) == 0 {
+			panic(fmt.Sprintf("Expected queries to be named by sqlc headers, like '-- name: GetUser :one', but got: %s", source))
+		}
+		for name, q := range queries {
+			c.named[name] = q
+		}
+	}
+}
+
+// namedQuery returns the sql regexp of the named query, which must
+// be of one of the commands
+func (c *sqlmock) namedQuery(name, expecter string, commands ...string) string {
+	c.Lock()
+	q, ok := c.named[name]
+	c.Unlock()
+
+	if !ok {
+		panic(fmt.Sprintf("Expected query '%s' to be named by NameQueries", name))
+	}
+	for _, command := range commands {
+		if q.command == command {
+			return q.sqlRegexStr
+		}
+	}
+	panic(fmt.Sprintf("Expected query '%s' of :%s command to be expected by other than %s", name, q.command, expecter))
+}
+
+func (c *sqlmock) ExpectNamed(name string) *ExpectedQuery {
+	return c.ExpectQuery(c.namedQuery(name, "ExpectNamed", "one", "many"))
+}
+
+func (c *sqlmock) ExpectNamedExec(name string) *ExpectedExec {
+	return c.ExpectExec(c.namedQuery(name, "ExpectNamedExec", "exec", "execrows", "execresult", "execlastid"))
+}
@@ -0,0 +1,91 @@
+package sqlmock
+
+import "testing"
+
+// as sqlc generates them, the header is a part of the query
+const (
+	getUser = `-- name: GetUser :one
+SELECT id, name FROM users
+WHERE id = $1 LIMIT 1
+`
+	deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+)
+
+const userQueries = `
+-- name: ListUsers :many
+SELECT id, name FROM users
+ORDER BY name;
+
+-- name: RenameUser :execrows
+UPDATE users SET name = $2
+WHERE id = $1;
+`
+
+func TestExpectNamed(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.NameQueries(getUser, deleteUser, userQueries)
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectNamed("GetUser").WithArgs(1).WillReturnRows(NewRows([]string{"id", "name"}).AddRow(1, "bob"))
+	mock.ExpectNamed("ListUsers").WillReturnRows(NewRows([]string{"id", "name"}).AddRow(1, "bob"))
+	mock.ExpectNamedExec("RenameUser").WithArgs(1, "alice").WillReturnResult(NewResult(0, 1))
+	mock.ExpectNamedExec("DeleteUser").WithArgs(1).WillReturnResult(NewResult(0, 1))
+
+	var name string
+	if err := db.QueryRow(getUser, 1).Scan(new(int), &name); err != nil || name != "bob" {
+		t.Errorf("expected the named query to return 'bob', but got: %q, %v", name, err)
+	}
+	rows, err := db.Query("SELECT id, name FROM users ORDER BY name")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected, since the query is named without its header", err)
+	} else {
+		rows.Close()
+	}
+	if _, err := db.Exec("-- name: RenameUser :execrows\nUPDATE users SET name = $2\nWHERE id = $1", 1, "alice"); err != nil {
+		t.Errorf("an error '%s' was not expected, since the query is named", err)
+	}
+	if _, err := db.Exec("DELETE FROM users WHERE id = $1 AND deleted_at IS NULL", 1); err == nil {
+		t.Error("expected a query, which only begins as the named one, not to match it")
+	}
+	if _, err := db.Exec(deleteUser, 1); err != nil {
+		t.Errorf("an error '%s' was not expected, since the query is named", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestExpectNamedPanics(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.NameQueries(getUser)
+
+	cases := map[string]func(){
+		"unnamed source": func() { mock.NameQueries("SELECT 1") },
+		"unknown name":   func() { mock.ExpectNamed("GetOrder") },
+		"wrong command":  func() { mock.ExpectNamedExec("GetUser") },
+	}
+	for name, expect := range cases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected %s to panic", name)
+				}
+			}()
+			expect()
+		}()
+	}
+}
@@ -0,0 +1,42 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"reflect"
+)
+
+// ArgNormalizer converts an argument to its normal form, so that
+// arguments which are equal in the domain are compared as equal,
+// like decimals with trailing zeros or emails in different case.
+type ArgNormalizer func(v driver.Value) driver.Value
+
+// normalizers of arguments by their type
+type normalizers map[reflect.Type]ArgNormalizer
+
+// apply normalizes the argument, if there is a normalizer for its type
+func (n normalizers) apply(v driver.Value) driver.Value {
+	if normalize, ok := n[reflect.TypeOf(v)]; ok {
+		return normalize(v)
+	}
+	return v
+}
+
+func (c *sqlmock) NormalizeArgs(example driver.Value, normalizer ArgNormalizer) {
+	c.Lock()
+	defer c.Unlock()
+
+	// copied on write, so that calls may use the normalizers unlocked
+	n := make(normalizers, len(c.normalizers)+1)
+	for typ, normalize := range c.normalizers {
+		n[typ] = normalize
+	}
+	n[reflect.TypeOf(example)] = normalizer
+	c.normalizers = n
+}
+
+// argNormalizers returns normalizers configured for the mock
+func (c *sqlmock) argNormalizers() normalizers {
+	c.Lock()
+	defer c.Unlock()
+	return c.normalizers
+}
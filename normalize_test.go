@@ -0,0 +1,42 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeArgs(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.NormalizeArgs("", func(v driver.Value) driver.Value {
+		s := v.(string)
+		if strings.Contains(s, "@") {
+			return strings.ToLower(s)
+		}
+		if strings.Contains(s, ".") {
+			return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		}
+		return s
+	})
+
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs("john@example.com", "10.5").
+		WillReturnResult(NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs("mark@example.com", "3").
+		WillReturnResult(NewResult(2, 1))
+
+	if _, err := db.Exec("INSERT INTO users (email, balance) VALUES (?, ?)", "John@Example.com", "10.500"); err != nil {
+		t.Errorf("error '%s' was not expected, since normalized arguments match", err)
+	}
+	if _, err := db.Exec("INSERT INTO users (email, balance) VALUES (?, ?)", "Mark@Example.com", "3.01"); err == nil {
+		t.Error("an error was expected, since normalized balance does not match")
+	}
+}
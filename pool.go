@@ -0,0 +1,82 @@
+package sqlmock
+
+import (
+	"fmt"
+	"time"
+)
+
+// PoolStats counts connections of the mock, see PoolInspector.PoolStats
+type PoolStats struct {
+	Open  int
+	InUse int
+	Idle  int
+}
+
+// PoolSample is PoolStats at a time of the simulated Clock
+type PoolSample struct {
+	At time.Time
+	PoolStats
+}
+
+// connsChanged mirrors the number of open connections, which is
+// guarded by the driver lock, to be sampled under the mock lock
+func (c *sqlmock) connsChanged(opened int) {
+	c.Lock()
+	c.openConns = opened
+	c.samplePool()
+	c.Unlock()
+}
+
+// poolStats counts connections, must be called under the mock lock
+func (c *sqlmock) poolStats() PoolStats {
+	stats := PoolStats{Open: c.openConns, InUse: c.inFlight}
+	if c.inTx && stats.InUse == 0 {
+		stats.InUse = 1
+	}
+	if stats.Idle = stats.Open - stats.InUse; stats.Idle < 0 {
+		stats.Idle = 0
+	}
+	return stats
+}
+
+// samplePool records the stats, if they changed while recording,
+// must be called under the mock lock
+func (c *sqlmock) samplePool() {
+	if !c.poolRecording {
+		return
+	}
+	stats := c.poolStats()
+	if n := len(c.poolHistory); n > 0 && c.poolHistory[n-1].PoolStats == stats {
+		return
+	}
+	c.poolHistory = append(c.poolHistory, PoolSample{At: c.clock.Now(), PoolStats: stats})
+}
+
+func (c *sqlmock) PoolStats() PoolStats {
+	c.Lock()
+	defer c.Unlock()
+	return c.poolStats()
+}
+
+func (c *sqlmock) RecordPoolStats() {
+	c.Lock()
+	c.poolRecording = true
+	c.samplePool()
+	c.Unlock()
+}
+
+func (c *sqlmock) PoolHistory() []PoolSample {
+	c.Lock()
+	defer c.Unlock()
+	return append([]PoolSample(nil), c.poolHistory...)
+}
+
+func (c *sqlmock) PoolStayedWithin(maxOpen, maxInUse int) error {
+	for _, s := range c.PoolHistory() {
+		if s.Open > maxOpen || s.InUse > maxInUse {
+			return fmt.Errorf("pool had %d connections open and %d in use at %s, but expected at most %d open and %d in use",
+				s.Open, s.InUse, s.At.Format(time.RFC3339Nano), maxOpen, maxInUse)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package sqlmock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolStats(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RecordPoolStats()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE jobs").WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if stats := mock.PoolStats(); stats != (PoolStats{Open: 1, InUse: 0, Idle: 1}) {
+		t.Errorf("expected one idle connection, but got: %+v", stats)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	mock.Clock().Advance(time.Second)
+	if _, err = tx.Exec("UPDATE jobs SET done = 1"); err != nil {
+		t.Errorf("an error '%s' was not expected while updating", err)
+	}
+	mock.Clock().Advance(time.Second)
+	if err = tx.Commit(); err != nil {
+		t.Errorf("an error '%s' was not expected when committing a transaction", err)
+	}
+
+	history := mock.PoolHistory()
+	var inUse []string
+	for _, s := range history {
+		inUse = append(inUse, fmt.Sprintf("%s=%d", s.At.Sub(history[0].At), s.InUse))
+	}
+	if got := strings.Join(inUse, " "); got != "0s=0 0s=1 2s=0" {
+		t.Errorf("expected the connection to be in use for the transaction, but got: %s", got)
+	}
+
+	if err := mock.PoolStayedWithin(1, 1); err != nil {
+		t.Errorf("an error '%s' was not expected, since one connection was used", err)
+	}
+	if err := mock.PoolStayedWithin(1, 0); err == nil || !strings.HasPrefix(err.Error(), "pool had 1 connections open and 1 in use at ") {
+		t.Errorf("expected an error about the connection in use, but got: %v", err)
+	}
+}
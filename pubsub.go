@@ -0,0 +1,335 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	RegisterExpectationKind("Listen", func(query string) bool {
+		return hasPrefixFold(query, "LISTEN ")
+	})
+	RegisterExpectationKind("Unlisten", func(query string) bool {
+		return hasPrefixFold(query, "UNLISTEN ")
+	})
+	RegisterExpectationKind("Notify", func(query string) bool {
+		return hasPrefixFold(query, "NOTIFY ") || hasPrefixFold(query, "SELECT pg_notify(")
+	})
+}
+
+// hasPrefixFold tells whether s begins with prefix ignoring case,
+// without allocating, since it is checked for every call
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// Notification is an event of postgres LISTEN/NOTIFY, delivered
+// to a channel being listened
+type Notification struct {
+	Channel string
+	Payload string
+}
+
+// PubSub simulates postgres LISTEN/NOTIFY for a mock. It queues
+// expectations of LISTEN, UNLISTEN and NOTIFY statements, keeps
+// track of the channels being listened and of notifications
+// delivered to them, which could be consumed by the polling code
+// under test:
+//
+//	ps := sqlmock.NewPubSub(mock)
+//	ps.ExpectListen("orders")
+//	ps.ExpectNotify("orders").WithPayload("42")
+//
+//	// in the code under test
+//	db.Exec("LISTEN orders")
+//	db.Exec("NOTIFY orders, '42'")
+//	n, ok := ps.Poll() // {orders 42}
+//
+// Notifications could be injected with Notify, as if they were sent
+// by another session. Same as postgres, a notification is delivered
+// only if its channel is being listened. Until an expectation of
+// a statement is queued by PubSub, the statement is matched to
+// ExpectExec and ExpectQuery expectations as usual.
+type PubSub struct {
+	mock CustomExpecter
+
+	mu        sync.Mutex
+	listening map[string]bool
+	queue     []Notification
+	ready     chan struct{}
+}
+
+// NewPubSub creates LISTEN/NOTIFY simulation for the mock
+func NewPubSub(mock CustomExpecter) *PubSub {
+	return &PubSub{
+		mock:      mock,
+		listening: make(map[string]bool),
+		ready:     make(chan struct{}, 1),
+	}
+}
+
+// ExpectListen expects a LISTEN statement of the channel to be
+// executed, once it is, notifications of the channel are delivered
+func (p *PubSub) ExpectListen(channel string) *ExpectedListen {
+	e := &ExpectedListen{pubsub: p, channel: channelName(channel)}
+	p.mock.ExpectCustom("Listen", e)
+	return e
+}
+
+// ExpectUnlisten expects an UNLISTEN statement of the channel, or
+// of all the channels if it is "*", to be executed
+func (p *PubSub) ExpectUnlisten(channel string) *ExpectedUnlisten {
+	e := &ExpectedUnlisten{pubsub: p, channel: channelName(channel)}
+	p.mock.ExpectCustom("Unlisten", e)
+	return e
+}
+
+// ExpectNotify expects a notification of the channel to be sent,
+// either by a NOTIFY statement or by pg_notify function. The sent
+// notification is delivered, if the channel is being listened.
+func (p *PubSub) ExpectNotify(channel string) *ExpectedNotify {
+	e := &ExpectedNotify{pubsub: p, channel: channelName(channel)}
+	p.mock.ExpectCustom("Notify", e)
+	return e
+}
+
+// Notify injects a notification as if it was sent by another
+// session and tells whether it was delivered, which happens only
+// if the channel is being listened
+func (p *PubSub) Notify(channel, payload string) bool {
+	return p.deliver(Notification{Channel: channelName(channel), Payload: payload})
+}
+
+// Listening tells whether the channel is being listened
+func (p *PubSub) Listening(channel string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.listening[channelName(channel)]
+}
+
+// Poll returns the oldest delivered notification, which was not
+// consumed yet, it does not wait if there is none
+func (p *PubSub) Poll() (Notification, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.queue) == 0 {
+		return Notification{}, false
+	}
+	n := p.queue[0]
+	p.queue = p.queue[1:]
+	return n, true
+}
+
+// WaitForNotification returns the oldest delivered notification,
+// waiting up to timeout for one to be delivered
+func (p *PubSub) WaitForNotification(timeout time.Duration) (Notification, error) {
+	deadline := time.After(timeout)
+	for {
+		if n, ok := p.Poll(); ok {
+			return n, nil
+		}
+		select {
+		case <-p.ready:
+		case <-deadline:
+			return Notification{}, fmt.Errorf("no notification was delivered within %s", timeout)
+		}
+	}
+}
+
+// deliver queues the notification, if its channel is being listened
+func (p *PubSub) deliver(n Notification) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.listening[n.Channel] {
+		return false
+	}
+	p.queue = append(p.queue, n)
+	select {
+	case p.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// listen starts or stops listening the channel, "*" stops all of them
+func (p *PubSub) listen(channel string, on bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case on:
+		p.listening[channel] = true
+	case channel == "*":
+		p.listening = make(map[string]bool)
+	default:
+		delete(p.listening, channel)
+	}
+}
+
+// ExpectedListen is used to manage a LISTEN statement
+// expected by PubSub.ExpectListen
+type ExpectedListen struct {
+	pubsub  *PubSub
+	channel string
+	err     error
+}
+
+// WillReturnError allows to set an error for the LISTEN statement,
+// the channel is not listened in that case
+func (e *ExpectedListen) WillReturnError(err error) *ExpectedListen {
+	e.err = err
+	return e
+}
+
+// Match implements CustomExpectation
+func (e *ExpectedListen) Match(query string, args []driver.Value) bool {
+	return channelName(statementOperand(query, "LISTEN ")) == e.channel
+}
+
+// Handle implements CustomExpectation
+func (e *ExpectedListen) Handle(query string, args []driver.Value) (driver.Result, driver.Rows, error) {
+	if e.err != nil {
+		return nil, nil, e.err
+	}
+	e.pubsub.listen(e.channel, true)
+	return driver.ResultNoRows, nil, nil
+}
+
+func (e *ExpectedListen) String() string {
+	return fmt.Sprintf("expecting LISTEN on channel '%s'", e.channel)
+}
+
+// ExpectedUnlisten is used to manage an UNLISTEN statement
+// expected by PubSub.ExpectUnlisten
+type ExpectedUnlisten struct {
+	pubsub  *PubSub
+	channel string
+	err     error
+}
+
+// WillReturnError allows to set an error for the UNLISTEN statement
+func (e *ExpectedUnlisten) WillReturnError(err error) *ExpectedUnlisten {
+	e.err = err
+	return e
+}
+
+// Match implements CustomExpectation
+func (e *ExpectedUnlisten) Match(query string, args []driver.Value) bool {
+	return channelName(statementOperand(query, "UNLISTEN ")) == e.channel
+}
+
+// Handle implements CustomExpectation
+func (e *ExpectedUnlisten) Handle(query string, args []driver.Value) (driver.Result, driver.Rows, error) {
+	if e.err != nil {
+		return nil, nil, e.err
+	}
+	e.pubsub.listen(e.channel, false)
+	return driver.ResultNoRows, nil, nil
+}
+
+func (e *ExpectedUnlisten) String() string {
+	return fmt.Sprintf("expecting UNLISTEN on channel '%s'", e.channel)
+}
+
+// ExpectedNotify is used to manage a notification
+// expected by PubSub.ExpectNotify
+type ExpectedNotify struct {
+	pubsub     *PubSub
+	channel    string
+	payload    string
+	hasPayload bool
+	err        error
+}
+
+// WithPayload expects the notification to be sent with the payload
+func (e *ExpectedNotify) WithPayload(payload string) *ExpectedNotify {
+	e.payload, e.hasPayload = payload, true
+	return e
+}
+
+// WillReturnError allows to set an error for the notification,
+// it is not delivered in that case
+func (e *ExpectedNotify) WillReturnError(err error) *ExpectedNotify {
+	e.err = err
+	return e
+}
+
+// Match implements CustomExpectation
+func (e *ExpectedNotify) Match(query string, args []driver.Value) bool {
+	n, ok := parseNotification(query, args)
+	return ok && n.Channel == e.channel && (!e.hasPayload || n.Payload == e.payload)
+}
+
+// Handle implements CustomExpectation
+func (e *ExpectedNotify) Handle(query string, args []driver.Value) (driver.Result, driver.Rows, error) {
+	if e.err != nil {
+		return nil, nil, e.err
+	}
+	n, _ := parseNotification(query, args)
+	e.pubsub.deliver(n)
+
+	if hasPrefixFold(query, "SELECT ") {
+		// pg_notify returns void, a single row of NULL
+		return nil, NewRows([]string{"pg_notify"}).AddRow(nil), nil
+	}
+	return driver.ResultNoRows, nil, nil
+}
+
+func (e *ExpectedNotify) String() string {
+	if e.hasPayload {
+		return fmt.Sprintf("expecting NOTIFY on channel '%s' with payload '%s'", e.channel, e.payload)
+	}
+	return fmt.Sprintf("expecting NOTIFY on channel '%s'", e.channel)
+}
+
+// parseNotification reads the notification sent by a NOTIFY statement,
+// or by pg_notify function called with channel and payload arguments
+func parseNotification(query string, args []driver.Value) (Notification, bool) {
+	if hasPrefixFold(query, "SELECT ") {
+		if len(args) != 2 {
+			return Notification{}, false
+		}
+		channel, _ := args[0].(string)
+		payload, _ := args[1].(string)
+		if b, ok := args[1].([]byte); ok {
+			payload = string(b)
+		}
+		// the function takes the channel name as is
+		return Notification{Channel: channel, Payload: payload}, true
+	}
+
+	operand := statementOperand(query, "NOTIFY ")
+	channel, payload := operand, ""
+	if i := strings.Index(operand, ","); i >= 0 {
+		channel = operand[:i]
+		payload = strings.TrimSpace(operand[i+1:])
+		if len(payload) < 2 || payload[0] != '\'' || payload[len(payload)-1] != '\'' {
+			return Notification{}, false
+		}
+		payload = strings.Replace(payload[1:len(payload)-1], "''", "'", -1)
+	}
+	return Notification{Channel: channelName(channel), Payload: payload}, true
+}
+
+// statementOperand returns what follows the keyword of the statement
+func statementOperand(query, keyword string) string {
+	if !hasPrefixFold(query, keyword) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(query[len(keyword):], "; "))
+}
+
+// channelName normalizes the channel identifier the way postgres
+// does, unquoted names are folded to lower case
+func channelName(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		return strings.Replace(name[1:len(name)-1], `""`, `"`, -1)
+	}
+	return strings.ToLower(name)
+}
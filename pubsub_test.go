@@ -0,0 +1,145 @@
+package sqlmock
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPubSubListenNotify(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	ps := NewPubSub(mock)
+	ps.ExpectListen("orders")
+	ps.ExpectNotify("orders").WithPayload("it's 42")
+	ps.ExpectNotify("Orders").WithPayload("43")
+	ps.ExpectUnlisten("orders")
+	ps.ExpectNotify("orders")
+
+	if _, err := db.Exec("LISTEN Orders;"); err != nil {
+		t.Errorf("error '%s' was not expected while listening", err)
+	}
+	if !ps.Listening("orders") {
+		t.Errorf("expected channel 'orders' to be listened")
+	}
+	if _, err := db.Exec("NOTIFY orders, 'it''s 42'"); err != nil {
+		t.Errorf("error '%s' was not expected while notifying", err)
+	}
+
+	var void interface{}
+	if err := db.QueryRow("SELECT pg_notify($1, $2)", "orders", "43").Scan(&void); err != nil {
+		t.Errorf("error '%s' was not expected while notifying", err)
+	}
+
+	if ok := ps.Notify("orders", "44"); !ok {
+		t.Errorf("expected the injected notification to be delivered")
+	}
+
+	var got []Notification
+	for {
+		n, ok := ps.Poll()
+		if !ok {
+			break
+		}
+		got = append(got, n)
+	}
+	if fmt.Sprint(got) != "[{orders it's 42} {orders 43} {orders 44}]" {
+		t.Errorf("unexpected notifications: %v", got)
+	}
+
+	if _, err := db.Exec("UNLISTEN orders"); err != nil {
+		t.Errorf("error '%s' was not expected while unlistening", err)
+	}
+	if _, err := db.Exec("NOTIFY orders"); err != nil {
+		t.Errorf("error '%s' was not expected while notifying", err)
+	}
+	if n, ok := ps.Poll(); ok {
+		t.Errorf("expected no notification to be delivered to a channel not listened, but got %v", n)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestPubSubNotifyPayloadMismatch(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	ps := NewPubSub(mock)
+	ps.ExpectNotify("orders").WithPayload("42")
+
+	_, err = db.Exec("NOTIFY orders, '41'")
+	if err == nil || err.Error() != "Notify 'NOTIFY orders, '41'' with args [], does not match expectation: expecting NOTIFY on channel 'orders' with payload '42'" {
+		t.Errorf("expected an error about the mismatching payload, but got: %v", err)
+	}
+}
+
+func TestListenWithoutPubSub(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	mock.ExpectExec("LISTEN events").WillReturnResult(NewResult(0, 0))
+
+	res, err := db.Exec("LISTEN events")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected, since LISTEN is expected by ExpectExec", err)
+	}
+	if _, err := res.RowsAffected(); err != nil {
+		t.Errorf("error '%s' was not expected, since the result of ExpectExec is returned", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPubSubWaitForNotification(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ps := NewPubSub(mock)
+	ps.ExpectListen("jobs")
+	if _, err := db.Exec("LISTEN jobs"); err != nil {
+		t.Fatalf("error '%s' was not expected while listening", err)
+	}
+
+	if _, err := ps.WaitForNotification(time.Millisecond); err == nil {
+		t.Errorf("expected an error, since no notification was delivered")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		ps.Notify("jobs", "run")
+	}()
+	n, err := ps.WaitForNotification(time.Second)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while waiting for a notification", err)
+	}
+	if n.Channel != "jobs" || n.Payload != "run" {
+		t.Errorf("unexpected notification: %v", n)
+	}
+
+	if ps.Notify("other", "run") {
+		t.Errorf("expected a notification of the channel not listened to be dropped")
+	}
+}
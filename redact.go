@@ -0,0 +1,118 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+)
+
+// redacted replaces the value of a sensitive argument in output
+const redacted = "[REDACTED]"
+
+// RedactionRule tells whether the argument at the index of a call is
+// sensitive, see RedactArgs. It must not call the mock.
+type RedactionRule func(index int, arg driver.Value) bool
+
+// RedactPositions masks arguments at the given indexes, which start at 0,
+// like the password of an INSERT INTO users statement
+func RedactPositions(indexes ...int) RedactionRule {
+	return func(index int, _ driver.Value) bool {
+		for _, i := range indexes {
+			if i == index {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RedactType masks arguments of the same type as example. Note that
+// database/sql converts arguments of custom types to driver values,
+// so that only expected arguments keep a custom type, like Password.
+func RedactType(example driver.Value) RedactionRule {
+	typ := reflect.TypeOf(example)
+	return func(_ int, arg driver.Value) bool {
+		return reflect.TypeOf(arg) == typ
+	}
+}
+
+// redactedArg is formatted as redacted, in place of an argument
+type redactedArg struct{}
+
+func (redactedArg) String() string {
+	return redacted
+}
+
+// redactions are the rules of the mock, which its expectations
+// share, the lock is taken after any other
+type redactions struct {
+	sync.Mutex
+	rules []RedactionRule
+}
+
+func (c *sqlmock) RedactArgs(rules ...RedactionRule) {
+	c.redact.Lock()
+	c.redact.rules = append(c.redact.rules, rules...)
+	c.redact.Unlock()
+}
+
+// redacts tells whether any rule masks the argument
+func (r *redactions) redacts(index int, arg driver.Value) bool {
+	if r == nil {
+		return false
+	}
+	r.Lock()
+	rules := r.rules
+	r.Unlock()
+
+	for _, rule := range rules {
+		if rule(index, arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// args returns a copy of arguments with the sensitive ones masked,
+// or the arguments themselves, if none is masked
+func (r *redactions) args(args []driver.Value) []driver.Value {
+	var masked []driver.Value
+	for i, arg := range args {
+		if !r.redacts(i, arg) {
+			continue
+		}
+		if masked == nil {
+			masked = append([]driver.Value(nil), args...)
+		}
+		masked[i] = redactedArg{}
+	}
+	if masked == nil {
+		return args
+	}
+	return masked
+}
+
+// argSets masks sensitive arguments of every set
+func (r *redactions) argSets(sets [][]driver.Value) [][]driver.Value {
+	masked := make([][]driver.Value, len(sets))
+	for i, args := range sets {
+		masked[i] = r.args(args)
+	}
+	return masked
+}
+
+// mismatch masks the values of the mismatch, if either of the
+// mismatching arguments is sensitive
+func (r *redactions) mismatch(m *ArgMismatch, expected, actual []driver.Value) *ArgMismatch {
+	if m == nil || m.Index < 0 {
+		return m
+	}
+	sensitive := m.Index < len(expected) && r.redacts(m.Index, expected[m.Index]) ||
+		m.Index < len(actual) && r.redacts(m.Index, actual[m.Index])
+	if !sensitive {
+		return m
+	}
+	masked := *m
+	masked.Expected, masked.Actual = redacted, redacted
+	return &masked
+}
@@ -0,0 +1,75 @@
+package sqlmock
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRedactArgsInErrors(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RedactArgs(RedactPositions(1))
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectExec("INSERT INTO users").WithArgs("bob", "hunter2").WillReturnResult(NewResult(1, 1))
+	mock.ExpectQuery("SELECT (.+) FROM users").WithArgs("bob", "hunter2").WillReturnRows(NewRows([]string{"id"}))
+
+	_, err = db.Exec("INSERT INTO users(name, password) VALUES (?, ?)", "bob", "s3cret")
+	if err == nil {
+		t.Fatal("expected an error, since the password does not match")
+	}
+	if msg := err.Error(); strings.Contains(msg, "hunter2") || strings.Contains(msg, "s3cret") || !strings.Contains(msg, "bob") {
+		t.Errorf("expected only the password to be redacted, but got: %s", msg)
+	}
+	if !strings.Contains(err.Error(), "argument 1 differs, expected [REDACTED], but got [REDACTED]") {
+		t.Errorf("expected the mismatch to be redacted, but got: %s", err)
+	}
+
+	_, err = db.Exec("UPDATE users SET password = ? WHERE name = ?", "s3cret", "bob")
+	if err == nil {
+		t.Fatal("expected an error, since the query is not expected next")
+	}
+	if msg := err.Error(); strings.Contains(msg, "hunter2") || !strings.Contains(msg, "1 - [REDACTED]") {
+		t.Errorf("expected the password of the next expectation to be redacted, but got: %s", msg)
+	}
+}
+
+type password string
+
+func TestRedactArgsInExports(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RedactArgs(RedactType(password("")), RedactType([]byte(nil)))
+	mock.RecordArgs()
+	mock.ExpectExec("INSERT INTO users").WithArgs("bob", password("hunter2"), []byte("078-05-1120")).WillReturnResult(NewResult(1, 1))
+
+	if _, err = db.Exec("INSERT INTO users(name, password, ssn) VALUES (?, ?, ?)", "bob", password("hunter2"), []byte("078-05-1120")); err != nil {
+		t.Errorf("an error '%s' was not expected, since the arguments match", err)
+	}
+
+	snapshot := mock.ArgsSnapshot()
+	if strings.Contains(snapshot, "078-05-1120") || !strings.Contains(snapshot, "2: [REDACTED]") {
+		t.Errorf("expected the []byte argument to be redacted, but got:\n%s", snapshot)
+	}
+	if !strings.Contains(snapshot, `1: "hunter2"`) {
+		t.Errorf("expected the password to be converted to a string, which is not redacted, but got:\n%s", snapshot)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteMarkdownReport(&buf, mock); err != nil {
+		t.Fatalf("an error '%s' was not expected when writing a report", err)
+	}
+	if report := buf.String(); strings.Contains(report, "hunter2") || strings.Contains(report, "078-05-1120") || !strings.Contains(report, "bob, [REDACTED], [REDACTED]") {
+		t.Errorf("expected the expected password and []byte argument to be redacted, but got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,147 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sync"
+)
+
+// Ref is a reference to a value, like an id, returned by a mocked
+// INSERT, which is bound once the insert is matched. It could be used
+// as an expected argument and as a value of rows of later expectations,
+// so that a multi step flow stays consistent when its fixtures change:
+//
+//	userID := sqlmock.NewRef("user_id")
+//	mock.ExpectExec("INSERT INTO users").
+//		WillReturnResult(userID.Capture(sqlmock.NewResult(42, 1)))
+//	mock.ExpectQuery("SELECT (.+) FROM users WHERE id = ?").
+//		WithArgs(userID).
+//		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(userID, "bob"))
+type Ref struct {
+	mu    sync.Mutex
+	name  string
+	value driver.Value
+	bound bool
+}
+
+// NewRef creates a named reference, which is not bound yet
+func NewRef(name string) *Ref {
+	return &Ref{name: name}
+}
+
+// Value returns the bound value and whether the reference is bound
+func (r *Ref) Value() (driver.Value, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.value, r.bound
+}
+
+// Match implements Argument, an argument matches the bound value,
+// it never matches while the reference is not bound
+func (r *Ref) Match(v driver.Value) bool {
+	value, bound := r.Value()
+	return bound && CompareArgs([]driver.Value{value}, []driver.Value{v}) == nil
+}
+
+func (r *Ref) String() string {
+	if value, bound := r.Value(); bound {
+		return fmt.Sprintf("ref %s (%+v)", r.name, value)
+	}
+	return fmt.Sprintf("ref %s (unbound)", r.name)
+}
+
+// bind sets the referenced value
+func (r *Ref) bind(v driver.Value) {
+	r.mu.Lock()
+	r.value, r.bound = v, true
+	r.mu.Unlock()
+}
+
+// Capture returns the result, which binds the reference to its last
+// insert id, once it is returned by a matched exec. The result may be
+// generated by Sequence.Result.
+func (r *Ref) Capture(res driver.Result) driver.Result {
+	return &capturingResult{Result: res, ref: r}
+}
+
+// CaptureColumn returns the rows, which bind the reference to the value
+// of the column of the first row, once they are returned by a matched
+// query, like the id of INSERT ... RETURNING. The rows must be created
+// by NewRows or Sequence.Returning.
+func (r *Ref) CaptureColumn(rs driver.Rows, column string) driver.Rows {
+	cr := &capturingRows{ref: r}
+	switch t := rs.(type) {
+	case *rows:
+		cr.rows, cr.source = t, t.cursor
+	case *returningRows:
+		cr.rows, cr.source = t.rows, t.next
+	default:
+		panic(fmt.Sprintf("Expected rows created by NewRows or Sequence.Returning, but got %T", rs))
+	}
+
+	for i, col := range cr.cols {
+		if col == column {
+			cr.column = i
+			return cr
+		}
+	}
+	panic(fmt.Sprintf("Expected column '%s' to be one of %v", column, cr.cols))
+}
+
+// resultGenerator generates the result of every matched exec
+type resultGenerator interface {
+	next() driver.Result
+}
+
+// rowsGenerator generates the rows of every matched query
+type rowsGenerator interface {
+	next() *rows
+}
+
+// capturingResult binds a reference to the last insert id
+type capturingResult struct {
+	driver.Result
+	ref *Ref
+}
+
+func (r *capturingResult) next() driver.Result {
+	res := r.Result
+	if g, ok := res.(resultGenerator); ok {
+		res = g.next()
+	}
+	if id, err := res.LastInsertId(); err == nil {
+		r.ref.bind(id)
+	}
+	return res
+}
+
+// capturingRows binds a reference to a value of the first row
+type capturingRows struct {
+	*rows
+	source func() *rows
+	ref    *Ref
+	column int
+}
+
+func (r *capturingRows) next() *rows {
+	rs := r.source()
+	if len(rs.rows) > 0 {
+		r.ref.bind(rs.rows[0][r.column])
+	}
+	return rs
+}
+
+// resolveRefs replaces references among the values of rows with the
+// bound values, an unbound reference is returned as NULL
+func resolveRefs(rs [][]driver.Value) [][]driver.Value {
+	resolved := make([][]driver.Value, len(rs))
+	for i, row := range rs {
+		resolved[i] = append([]driver.Value(nil), row...)
+		for j, v := range row {
+			if ref, ok := v.(*Ref); ok {
+				resolved[i][j], _ = ref.Value()
+			}
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,77 @@
+package sqlmock
+
+import (
+	"testing"
+)
+
+func TestRefLinksInsertsAndSelects(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	userID, orderID := NewRef("user_id"), NewRef("order_id")
+	orders := mock.Sequence("orders_id")
+	orders.Restart(100)
+
+	mock.ExpectExec("INSERT INTO users").
+		WillReturnResult(userID.Capture(NewResult(42, 1)))
+	mock.ExpectQuery("INSERT INTO orders").
+		WithArgs(userID).
+		WillReturnRows(orderID.CaptureColumn(orders.Returning(NewRows([]string{"id", "user_id"}).AddRow(nil, userID), "id"), "id"))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = ?").
+		WithArgs(orderID).
+		WillReturnRows(NewRows([]string{"id", "user_id"}).AddRow(orderID, userID))
+
+	res, err := db.Exec("INSERT INTO users(name) VALUES(?)", "bob")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while inserting", err)
+	}
+	uid, _ := res.LastInsertId()
+
+	var oid, owner int64
+	if err := db.QueryRow("INSERT INTO orders(user_id) VALUES(?) RETURNING id, user_id", uid).Scan(&oid, &owner); err != nil {
+		t.Fatalf("error '%s' was not expected while inserting", err)
+	}
+	if oid != 100 || owner != 42 {
+		t.Errorf("expected order 100 of user 42, but got order %d of user %d", oid, owner)
+	}
+
+	var id, user int64
+	if err := db.QueryRow("SELECT id, user_id FROM orders WHERE id = ?", oid).Scan(&id, &user); err != nil {
+		t.Fatalf("error '%s' was not expected while selecting", err)
+	}
+	if id != 100 || user != 42 {
+		t.Errorf("expected order 100 of user 42, but got order %d of user %d", id, user)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestRefMismatch(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	userID := NewRef("user_id")
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(userID.Capture(NewResult(42, 1)))
+	mock.ExpectExec("DELETE FROM users").WithArgs(userID).WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("INSERT INTO users(name) VALUES(?)", "bob"); err != nil {
+		t.Fatalf("error '%s' was not expected while inserting", err)
+	}
+
+	_, err = db.Exec("DELETE FROM users WHERE id = ?", 41)
+	if err == nil || err.Error() != "exec query 'DELETE FROM users WHERE id = ?', args [41] does not match expected [ref user_id (42)]: argument 0 is not matched by argument matcher, expected ref user_id (42), but got 41" {
+		t.Errorf("expected an error about the mismatching reference, but got: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package sqlmock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// handle is a connection to the mock made through one of several
+// database handles sharing it, calls and errors are tagged with it
+type handle struct {
+	*sqlmock
+	tag string
+}
+
+func (h *handle) Close() error {
+	return h.close(h.tag)
+}
+
+func (h *handle) Begin() (driver.Tx, error) {
+	return h.begin(h.tag, driver.TxOptions{})
+}
+
+func (h *handle) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return h.exec(context.Background(), h.tag, query, args)
+}
+
+func (h *handle) Prepare(query string) (driver.Stmt, error) {
+	return h.prepare(h.tag, query)
+}
+
+func (h *handle) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return h.query(context.Background(), h.tag, query, args)
+}
+
+// NewWithReplicas creates a primary and the given number of replica
+// database handles, which are all backed by a single mock to manage
+// expectations. So that replica selection logic, like round-robin,
+// could be tested against one set of expectations. Errors of the
+// mock are tagged with the handle the call was made on, which is
+// either "primary" or "replica-N", numbered from 1. Expectations may
+// be bound to a handle with OnHandle.
+func NewWithReplicas(replicas int) (primary *sql.DB, replicaDBs []*sql.DB, mock Sqlmock, err error) {
+	pool.Lock()
+	dsn := fmt.Sprintf("sqlmock_db_%d", pool.counter)
+	pool.counter++
+
+	smock := newMock(dsn)
+	pool.conns[dsn] = smock
+
+	tags := []string{"primary"}
+	for i := 1; i <= replicas; i++ {
+		tags = append(tags, fmt.Sprintf("replica-%d", i))
+	}
+	for _, tag := range tags {
+		hdsn := dsn + "_" + tag
+		pool.handles[hdsn] = &handle{smock, tag}
+		smock.handles = append(smock.handles, hdsn)
+	}
+	pool.Unlock()
+
+	dbs := make([]*sql.DB, len(tags))
+	for i, tag := range tags {
+		if dbs[i], err = sql.Open("sqlmock", dsn+"_"+tag); err != nil {
+			return nil, nil, smock, err
+		}
+		if err = dbs[i].Ping(); err != nil {
+			return nil, nil, smock, err
+		}
+	}
+	return dbs[0], dbs[1:], smock, nil
+}
+
+func (c *sqlmock) ReplicationLag(d time.Duration) {
+	c.Lock()
+	c.lag = d
+	c.Unlock()
+}
+
+// replicate records when tables of the statement were written,
+// if it was executed on the primary handle of lagging replicas
+func (c *sqlmock) replicate(handle, query string) {
+	if handle != "primary" {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.lag <= 0 {
+		return
+	}
+	if c.written == nil {
+		c.written = make(map[string]time.Time)
+	}
+	now := c.clock.Now()
+	for _, table := range statementTables(query) {
+		c.written[strings.ToLower(table)] = now
+	}
+}
+
+// lagging tells whether the query on a replica handle reads a table,
+// which was written on the primary less than the lag ago
+func (c *sqlmock) lagging(handle, query string) bool {
+	if !strings.HasPrefix(handle, "replica-") {
+		return false
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.written) == 0 {
+		return false
+	}
+	now := c.clock.Now()
+	for _, table := range statementTables(query) {
+		if at, ok := c.written[strings.ToLower(table)]; ok && now.Sub(at) < c.lag {
+			return true
+		}
+	}
+	return false
+}
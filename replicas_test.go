@@ -0,0 +1,116 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewWithReplicas(t *testing.T) {
+	t.Parallel()
+	primary, replicas, mock, err := NewWithReplicas(2)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer primary.Close()
+	for _, r := range replicas {
+		defer r.Close()
+	}
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectQuery("SELECT name FROM users").OnHandle("replica-1").WillReturnRows(NewRows([]string{"name"}).AddRow("bob"))
+	mock.ExpectQuery("SELECT name FROM users").OnHandle("replica-2").WillReturnRows(NewRows([]string{"name"}).AddRow("bob"))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").OnHandle("primary").WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// round-robin reads between replicas
+	for _, db := range []*sql.DB{replicas[0], replicas[1]} {
+		var name string
+		if err = db.QueryRow("SELECT name FROM users").Scan(&name); err != nil {
+			t.Errorf("error '%s' was not expected while reading from replica", err)
+		}
+	}
+
+	tx, err := primary.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err = tx.Exec("UPDATE users SET name = 'alice'"); err != nil {
+		t.Errorf("error '%s' was not expected while writing to primary", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while commiting a transaction", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestNewWithReplicasTagsErrors(t *testing.T) {
+	t.Parallel()
+	primary, replicas, mock, err := NewWithReplicas(1)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer primary.Close()
+	defer replicas[0].Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectExec("DELETE FROM users").OnHandle("primary").WillReturnResult(NewResult(0, 1))
+
+	_, err = replicas[0].Exec("DELETE FROM users")
+	if err == nil || !strings.HasPrefix(err.Error(), "replica-1: exec query 'DELETE FROM users' was expected to be made on handle primary") {
+		t.Errorf("expected an error tagged with the replica, but got: %v", err)
+	}
+}
+
+func TestReplicationLag(t *testing.T) {
+	t.Parallel()
+	primary, replicas, mock, err := NewWithReplicas(1)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer primary.Close()
+	defer replicas[0].Close()
+
+	mock.ReplicationLag(time.Second)
+	mock.ExpectExec("INSERT INTO users").OnHandle("primary").WillReturnResult(NewResult(1, 1))
+	mock.ExpectQuery("SELECT name FROM users").OnHandle("replica-1").
+		WillReturnRows(NewRows([]string{"name"}).AddRow("bob")).Times(2)
+	mock.ExpectQuery("SELECT name FROM users").OnHandle("primary").
+		WillReturnRows(NewRows([]string{"name"}).AddRow("bob"))
+	mock.ExpectQuery("SELECT title FROM posts").OnHandle("replica-1").
+		WillReturnRows(NewRows([]string{"title"}).AddRow("hello"))
+
+	if _, err := primary.Exec("INSERT INTO users(name) VALUES(?)", "bob"); err != nil {
+		t.Fatalf("error '%s' was not expected while writing to primary", err)
+	}
+
+	// read your writes falls back to primary, while replica lags
+	var name string
+	err = replicas[0].QueryRow("SELECT name FROM users WHERE id = ?", 1).Scan(&name)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected lagging replica to return no rows, but got: %v", err)
+	}
+	if err := primary.QueryRow("SELECT name FROM users WHERE id = ?", 1).Scan(&name); err != nil || name != "bob" {
+		t.Errorf("expected to read the written row from primary, but got '%s' and error: %v", name, err)
+	}
+
+	// tables not written are not lagging
+	var title string
+	if err := replicas[0].QueryRow("SELECT title FROM posts").Scan(&title); err != nil {
+		t.Errorf("error '%s' was not expected while reading from replica", err)
+	}
+
+	mock.Clock().Advance(time.Second)
+	if err := replicas[0].QueryRow("SELECT name FROM users WHERE id = ?", 1).Scan(&name); err != nil || name != "bob" {
+		t.Errorf("expected the written row to be replicated, but got '%s' and error: %v", name, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
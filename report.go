@@ -0,0 +1,232 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// reportEntry describes a single expectation in a report
+type reportEntry struct {
+	Number    int
+	Kind      string
+	SQL       string
+	Args      string
+	Returns   string
+	Fulfilled bool
+}
+
+// reportEntries snapshots all expectations queued on mock
+func reportEntries(mock SqlmockCommon) ([]reportEntry, error) {
+	c, ok := mock.(*sqlmock)
+	if !ok {
+		return nil, fmt.Errorf("cannot report expectations of %T, only sqlmock created mocks are supported", mock)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	entries := make([]reportEntry, len(c.expected))
+	for i, e := range c.expected {
+		e.Lock()
+		entries[i] = describe(e)
+		entries[i].Number = i + 1
+		entries[i].Fulfilled = e.fulfilled() || e.reusable()
+		e.Unlock()
+	}
+	return entries, nil
+}
+
+// describe summarizes expectation for a report
+func describe(e expectation) (entry reportEntry) {
+	var err error
+	switch t := e.(type) {
+	case *ExpectedClose:
+		entry.Kind, err = "Close", t.err
+	case *ExpectedConnect:
+		entry.Kind, err = "Connect", t.err
+	case *ExpectedBegin:
+		entry.Kind, err = "Begin", t.err
+	case *ExpectedCommit:
+		entry.Kind, err = "Commit", t.err
+	case *ExpectedRollback:
+		entry.Kind, err = "Rollback", t.err
+	case *ExpectedCancel:
+		entry.Kind = "Cancel"
+	case *ExpectedPrepare:
+		entry.Kind, entry.SQL, err = "Prepare", t.sqlRegex.String(), t.err
+	case *ExpectedQuery:
+		entry.Kind, entry.SQL, err = "Query", t.sqlRegex.String(), t.err
+		if t.queryRow {
+			entry.Kind = "QueryRow"
+		}
+		entry.Args = describeArgs(t.redact.args(t.args))
+		if rs, ok := t.rows.(*rows); ok {
+			entry.Returns = fmt.Sprintf("%d rows of columns: %s", len(rs.rows), strings.Join(rs.cols, ", "))
+		} else if t.rows != nil {
+			entry.Returns = fmt.Sprintf("rows %T", t.rows)
+		}
+	case *ExpectedExec:
+		entry.Kind, entry.SQL, err = "Exec", t.sqlRegex.String(), t.err
+		entry.Args = describeArgs(t.redact.args(t.args))
+		if t.result == driver.ResultNoRows {
+			entry.Returns = "no rows"
+		} else if res, ok := t.result.(*result); ok {
+			entry.Returns = fmt.Sprintf("last insert id: %d, rows affected: %d", res.insertID, res.rowsAffected)
+			if res.err != nil {
+				entry.Returns = fmt.Sprintf("result error: %s", res.err)
+			}
+		} else if t.result != nil {
+			entry.Returns = fmt.Sprintf("result %T", t.result)
+		}
+	case *customExpectation:
+		entry.Kind, entry.SQL = t.kind, t.custom.String()
+	default:
+		entry.Kind = fmt.Sprintf("%T", e)
+	}
+
+	if err != nil {
+		entry.Returns = fmt.Sprintf("error: %s", err)
+	}
+	return
+}
+
+// Report is a machine readable summary of expectations, returned by
+// Reporter.Report
+type Report struct {
+	Total      int `json:"total"`
+	Met        int `json:"met"`
+	Unmet      int `json:"unmet"`
+	Unexpected int `json:"unexpected"` // calls, which did not match any expectation
+
+	// Fulfillment is the percentage of met expectations,
+	// 100 if there were none
+	Fulfillment float64 `json:"fulfillment"`
+
+	Kinds        map[string]KindReport `json:"kinds"`
+	Expectations []ExpectationReport   `json:"expectations"`
+}
+
+// KindReport counts expectations of a kind, like Query or Exec
+type KindReport struct {
+	Total int `json:"total"`
+	Met   int `json:"met"`
+	Unmet int `json:"unmet"`
+}
+
+// ExpectationReport describes an expectation in a Report, its number
+// is the position in the order expectations were queued, from 1
+type ExpectationReport struct {
+	Number int    `json:"number"`
+	Kind   string `json:"kind"`
+	SQL    string `json:"sql,omitempty"`
+	Args   string `json:"args,omitempty"`
+	Met    bool   `json:"met"`
+}
+
+func (c *sqlmock) Report() Report {
+	entries, _ := reportEntries(c)
+
+	c.Lock()
+	report := Report{
+		Total:        len(entries),
+		Unexpected:   c.unexpected,
+		Fulfillment:  100,
+		Kinds:        make(map[string]KindReport),
+		Expectations: make([]ExpectationReport, len(entries)),
+	}
+	c.Unlock()
+
+	for i, e := range entries {
+		kind := report.Kinds[e.Kind]
+		kind.Total++
+		if e.Fulfilled {
+			kind.Met++
+			report.Met++
+		} else {
+			kind.Unmet++
+			report.Unmet++
+		}
+		report.Kinds[e.Kind] = kind
+		report.Expectations[i] = ExpectationReport{Number: e.Number, Kind: e.Kind, SQL: e.SQL, Args: e.Args, Met: e.Fulfilled}
+	}
+	if report.Total > 0 {
+		report.Fulfillment = float64(report.Met) * 100 / float64(report.Total)
+	}
+	return report
+}
+
+func describeArgs(args []driver.Value) string {
+	if args == nil {
+		return "any"
+	}
+	s := make([]string, len(args))
+	for i, arg := range args {
+		s[i] = fmt.Sprintf("%+v", arg)
+	}
+	return strings.Join(s, ", ")
+}
+
+// WriteMarkdownReport renders all expectations queued on mock and
+// whether they were fulfilled as a markdown table, so that database
+// behavior asserted by a test could be reviewed without reading it.
+func WriteMarkdownReport(w io.Writer, mock SqlmockCommon) error {
+	entries, err := reportEntries(mock)
+	if err != nil {
+		return err
+	}
+
+	cell := strings.NewReplacer("|", "\\|", "\n", " ")
+	var met int
+	var buf []string
+	for _, e := range entries {
+		status := "pending"
+		if e.Fulfilled {
+			status, met = "met", met+1
+		}
+		buf = append(buf, fmt.Sprintf("| %d | %s | %s | %s | %s | %s |",
+			e.Number, e.Kind, cell.Replace(e.SQL), cell.Replace(e.Args), cell.Replace(e.Returns), status))
+	}
+
+	header := fmt.Sprintf("%d of %d expectations met\n\n", met, len(entries))
+	header += "| # | Expectation | SQL | Arguments | Returns | Status |\n"
+	header += "|---|-------------|-----|-----------|---------|--------|\n"
+	if len(buf) > 0 {
+		header += strings.Join(buf, "\n") + "\n"
+	}
+
+	_, err = io.WriteString(w, header)
+	return err
+}
+
+var htmlReport = template.Must(template.New("report").Parse(`<table class="sqlmock-report">
+<caption>{{.Met}} of {{len .Entries}} expectations met</caption>
+<thead><tr><th>#</th><th>Expectation</th><th>SQL</th><th>Arguments</th><th>Returns</th><th>Status</th></tr></thead>
+<tbody>{{range .Entries}}
+<tr class="{{if .Fulfilled}}met{{else}}pending{{end}}"><td>{{.Number}}</td><td>{{.Kind}}</td><td><code>{{.SQL}}</code></td><td>{{.Args}}</td><td>{{.Returns}}</td><td>{{if .Fulfilled}}met{{else}}pending{{end}}</td></tr>{{end}}
+</tbody>
+</table>
+`))
+
+// WriteHTMLReport renders all expectations queued on mock and
+// whether they were fulfilled as an html table.
+func WriteHTMLReport(w io.Writer, mock SqlmockCommon) error {
+	entries, err := reportEntries(mock)
+	if err != nil {
+		return err
+	}
+
+	var met int
+	for _, e := range entries {
+		if e.Fulfilled {
+			met++
+		}
+	}
+
+	return htmlReport.Execute(w, struct {
+		Met     int
+		Entries []reportEntry
+	}{met, entries})
+}
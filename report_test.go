@@ -0,0 +1,107 @@
+package sqlmock
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownReport(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET name = (.+)|(.+)").WithArgs("bob", 5).WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("deadlock"))
+
+	if _, err = db.Begin(); err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteMarkdownReport(&buf, mock); err != nil {
+		t.Fatalf("an error '%s' was not expected while writing report", err)
+	}
+
+	expected := `1 of 3 expectations met
+
+| # | Expectation | SQL | Arguments | Returns | Status |
+|---|-------------|-----|-----------|---------|--------|
+| 1 | Begin |  |  |  | met |
+| 2 | Exec | UPDATE users SET name = (.+)\|(.+) | bob, 5 | last insert id: 0, rows affected: 1 | pending |
+| 3 | Commit |  |  | error: deadlock | pending |
+`
+	if buf.String() != expected {
+		t.Errorf("expected report:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+func TestHTMLReport(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE name <> ?").
+		WithArgs("<script>").
+		WillReturnRows(NewRows([]string{"id", "name"}).AddRow(1, "bob"))
+
+	var buf bytes.Buffer
+	if err = WriteHTMLReport(&buf, mock); err != nil {
+		t.Fatalf("an error '%s' was not expected while writing report", err)
+	}
+
+	for _, part := range []string{
+		"<caption>0 of 1 expectations met</caption>",
+		`<tr class="pending"><td>1</td><td>Query</td><td><code>SELECT (.&#43;) FROM users WHERE name &lt;&gt; ?</code></td>`,
+		"<td>&lt;script&gt;</td><td>1 rows of columns: id, name</td><td>pending</td></tr>",
+	} {
+		if !strings.Contains(buf.String(), part) {
+			t.Errorf("expected report to contain %s, but got:\n%s", part, buf.String())
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WithArgs("bob").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err = tx.Exec("UPDATE users SET name = ?", "bob"); err != nil {
+		t.Errorf("an error '%s' was not expected while updating", err)
+	}
+	if _, err = tx.Exec("INSERT INTO users(name) VALUES(?)", "bob"); err == nil {
+		t.Errorf("expected an error, since the insert was not expected")
+	}
+
+	report := mock.Report()
+	if report.Total != 4 || report.Met != 2 || report.Unmet != 2 || report.Unexpected != 1 || report.Fulfillment != 50 {
+		t.Errorf("expected 2 of 4 expectations met and 1 unexpected call, but got: %+v", report)
+	}
+	if exec := report.Kinds["Exec"]; exec.Total != 2 || exec.Met != 1 || exec.Unmet != 1 {
+		t.Errorf("expected 1 of 2 exec expectations met, but got: %+v", exec)
+	}
+	if e := report.Expectations[2]; e.Number != 3 || e.Kind != "Exec" || e.SQL != "DELETE FROM users" || e.Met {
+		t.Errorf("expected the delete to be reported as unmet, but got: %+v", e)
+	}
+}
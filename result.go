@@ -2,6 +2,7 @@ package sqlmock
 
 import (
 	"database/sql/driver"
+	"strings"
 )
 
 // Result satisfies sql driver Result, which
@@ -37,3 +38,79 @@ func (r *result) LastInsertId() (int64, error) {
 func (r *result) RowsAffected() (int64, error) {
 	return r.rowsAffected, r.err
 }
+
+// ResultWarning is a warning of a statement, as listed by mysql
+// SHOW WARNINGS, for example a value truncated on insert
+type ResultWarning struct {
+	Level   string // Note, Warning or Error
+	Code    int
+	Message string
+}
+
+// WarningsResult is implemented by results created with
+// NewResultWithWarnings. Since database/sql wraps the driver result,
+// it may be asserted on the result of the driver connection, as code
+// reading the warning count of a mysql driver result does.
+type WarningsResult interface {
+	driver.Result
+	WarningCount() int
+	Warnings() []ResultWarning
+}
+
+type warningsResult struct {
+	result
+	warnings []ResultWarning
+}
+
+// NewResultWithWarnings creates a new sql driver Result, which reports
+// warnings of the statement. Until the next Exec call, the warnings
+// are also returned by SHOW WARNINGS, SHOW COUNT(*) WARNINGS and
+// SELECT @@warning_count queries, unless they match any expectation.
+func NewResultWithWarnings(lastInsertID int64, rowsAffected int64, warnings ...ResultWarning) driver.Result {
+	return &warningsResult{
+		result:   result{insertID: lastInsertID, rowsAffected: rowsAffected},
+		warnings: warnings,
+	}
+}
+
+func (r *warningsResult) WarningCount() int {
+	return len(r.warnings)
+}
+
+func (r *warningsResult) Warnings() []ResultWarning {
+	return append([]ResultWarning(nil), r.warnings...)
+}
+
+// recordWarnings keeps warnings of the result of the last Exec call
+func (c *sqlmock) recordWarnings(res driver.Result) {
+	w, _ := res.(WarningsResult)
+	c.Lock()
+	c.lastWarnings = w
+	c.Unlock()
+}
+
+// warningRows answers a query of warnings of the last Exec call,
+// if it returned a result with warnings
+func (c *sqlmock) warningRows(query string) driver.Rows {
+	c.Lock()
+	w := c.lastWarnings
+	c.Unlock()
+	if w == nil {
+		return nil
+	}
+
+	query = strings.TrimSuffix(query, ";")
+	switch {
+	case strings.EqualFold(query, "SHOW WARNINGS"):
+		rs := NewRows([]string{"Level", "Code", "Message"})
+		for _, warning := range w.Warnings() {
+			rs.AddRow(warning.Level, int64(warning.Code), warning.Message)
+		}
+		return rs
+	case strings.EqualFold(query, "SHOW COUNT(*) WARNINGS"):
+		return NewRows([]string{"@@session.warning_count"}).AddRow(int64(w.WarningCount()))
+	case strings.EqualFold(query, "SELECT @@warning_count"), strings.EqualFold(query, "SELECT @@session.warning_count"):
+		return NewRows([]string{query[len("SELECT "):]}).AddRow(int64(w.WarningCount()))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package sqlmock
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"testing"
 )
@@ -25,10 +26,11 @@ func ExampleNewResult() {
 	fmt.Println(mock.ExpectationsWereMet())
 	// Output: there is a remaining expectation which was not matched: ExpectedExec => expecting Exec which:
 	//   - matches sql: '^INSERT (.+)'
-	//   - is without arguments
+	//   - is with any arguments
 	//   - should return Result having:
 	//       LastInsertId: 0
 	//       RowsAffected: 0
+	//   - is pending
 }
 
 func TestShouldReturnValidSqlDriverResult(t *testing.T) {
@@ -60,3 +62,39 @@ func TestShouldReturnErroeSqlDriverResult(t *testing.T) {
 		t.Error("expected error, but got none")
 	}
 }
+
+func TestResultWithWarnings(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	truncated := ResultWarning{Level: "Warning", Code: 1265, Message: "Data truncated for column 'name' at row 1"}
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(NewResultWithWarnings(1, 1, truncated))
+
+	res, err := mock.(driver.Execer).Exec("INSERT INTO users(name) VALUES(?)", []driver.Value{"a very long name"})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected while inserting", err)
+	}
+	w, ok := res.(WarningsResult)
+	if !ok || w.WarningCount() != 1 || w.Warnings()[0] != truncated {
+		t.Errorf("expected the result to report the truncation warning, but got: %+v", res)
+	}
+
+	var level, message string
+	var code int
+	if err := db.QueryRow("SHOW WARNINGS").Scan(&level, &code, &message); err != nil {
+		t.Fatalf("an error '%s' was not expected while showing warnings", err)
+	}
+	if level != truncated.Level || code != truncated.Code || message != truncated.Message {
+		t.Errorf("expected the truncation warning, but got: %s %d %s", level, code, message)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT @@warning_count").Scan(&count); err != nil || count != 1 {
+		t.Errorf("expected a warning count of 1, but got %d: %v", count, err)
+	}
+}
@@ -0,0 +1,14 @@
+package sqlmock
+
+import "database/sql/driver"
+
+// QueryRewriter rewrites the query and arguments of a call, before
+// it is matched to expectations, see RewriteQueries. It must not
+// call the mock.
+type QueryRewriter func(query string, args []driver.Value) (string, []driver.Value)
+
+func (c *sqlmock) RewriteQueries(rewriters ...QueryRewriter) {
+	c.Lock()
+	c.rewriters = append(c.rewriters, rewriters...)
+	c.Unlock()
+}
@@ -0,0 +1,39 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"testing"
+)
+
+func TestRewriteQueries(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	// undo a middleware, which adds optimizer hints and shards tables
+	hint := regexp.MustCompile(`/\*\+[^*]*\*/\s*`)
+	shard := regexp.MustCompile(`\busers_\d+\b`)
+	mock.RewriteQueries(
+		func(query string, args []driver.Value) (string, []driver.Value) {
+			return hint.ReplaceAllString(query, ""), args
+		},
+		func(query string, args []driver.Value) (string, []driver.Value) {
+			return shard.ReplaceAllString(query, "users"), args[1:] // the shard key
+		},
+	)
+	mock.RequireExpectations(true)
+	mock.ExpectExec("^UPDATE users SET name = \\? WHERE id = \\?$").
+		WithArgs("bob", 5).
+		WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("UPDATE /*+ MAX_EXECUTION_TIME(1000) */ users_7 SET name = ? WHERE id = ?", 7, "bob", 5); err != nil {
+		t.Errorf("an error '%s' was not expected, since the query is rewritten", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
@@ -3,7 +3,11 @@ package sqlmock
 import (
 	"database/sql/driver"
 	"encoding/csv"
+	"fmt"
 	"io"
+	"math"
+	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -27,7 +31,9 @@ type Rows interface {
 	// AddRow composed from database driver.Value slice
 	// return the same instance to perform subsequent actions.
 	// Note that the number of values must match the number
-	// of columns
+	// of columns, otherwise the row is not added and the error
+	// is returned by Err, see RowsBuildReporter, and by the query,
+	// which matches an expectation returning these rows.
 	AddRow(columns ...driver.Value) Rows
 
 	// FromCSVString build rows from csv string.
@@ -49,14 +55,120 @@ type Rows interface {
 	// when rows.Next() EOF was not yet reached, that is
 	// a default sql library behavior
 	CloseError(err error) Rows
+
+	// ReuseBuffers makes string and []byte values to be
+	// returned in buffers owned by rows, which are wiped
+	// and reused on every rows.Next and rows.Close call,
+	// the way real drivers do. So that sql.RawBytes
+	// scanned values, which are kept for longer than
+	// allowed, are invalidated like in production.
+	ReuseBuffers() Rows
+
+	// TruncateColumn injects a fault, which truncates string
+	// and []byte values of the given column to length bytes,
+	// in order to test how application validates scanned
+	// values.
+	TruncateColumn(column string, length int) Rows
+
+	// OverflowColumn injects a fault, which replaces numeric
+	// values of the given column with the largest value of
+	// 64 bit type, which is out of range for most of the
+	// types, values are scanned into.
+	OverflowColumn(column string) Rows
+}
+
+// RowsMapper is an extension of Rows, which adds rows
+// of values keyed by column name
+type RowsMapper interface {
+
+	// AddRowMap adds a row of values keyed by column name, so that
+	// values of wide tables need not be ordered. A column missing in
+	// the map is NULL, unless RequireColumns is set. A key, which is
+	// not a column, is an error, reported the same way as by AddRow.
+	AddRowMap(values map[string]interface{}) Rows
+
+	// RequireColumns makes a row added by AddRowMap afterwards, which
+	// lacks a value of any column, to be an error instead of NULL.
+	RequireColumns() Rows
+}
+
+// RowsLoader is an extension of Rows, which builds
+// rows from templates and json
+type RowsLoader interface {
+
+	// FromCSVTemplate renders text/template tmpl with data
+	// and builds rows from the resulting csv string, so that
+	// a mostly static fixture could embed values of a test,
+	// like identifiers or dates.
+	FromCSVTemplate(tmpl string, data interface{}) Rows
+
+	// FromJSONString build rows from json array of objects,
+	// which have a key for every column. Numbers become int64
+	// or float64 values and nested arrays or objects are kept
+	// as raw json []byte values.
+	FromJSONString(s string) Rows
+
+	// FromJSONTemplate renders text/template tmpl with data
+	// and builds rows from the resulting json string.
+	FromJSONTemplate(tmpl string, data interface{}) Rows
+}
+
+// RowsConverter is an extension of Rows, which
+// configures conversion of added values
+type RowsConverter interface {
+
+	// ValueConverter sets the converter of values implementing
+	// driver.Valuer, which are given to AddRow afterwards. Such
+	// values are converted when the row is added, a value which
+	// fails to convert is reported by Validate and returned as
+	// the error of its row, instead of handing it to database/sql.
+	//
+	// By default driver.DefaultParameterConverter is used.
+	ValueConverter(converter driver.ValueConverter) Rows
+}
+
+// RowsBuildReporter is an extension of Rows, which
+// reports errors of building rows
+type RowsBuildReporter interface {
+
+	// Err returns the first error recorded while building rows,
+	// so that generated fixtures could be checked gracefully.
+	Err() error
+}
+
+// RowsColumnsAllower is an extension of Rows, which
+// exempts rows from validation of columns
+type RowsColumnsAllower interface {
+
+	// AllowAnyColumns allows the rows to have no columns or duplicate
+	// column names, even if columns are validated, see ValidateColumns.
+	AllowAnyColumns() Rows
 }
 
 type rows struct {
 	cols     []string
+	defs     []*Column
 	rows     [][]driver.Value
 	pos      int
 	nextErr  map[int]error
 	closeErr error
+
+	reuse   bool
+	buffers [][]byte
+
+	faults map[int]func(driver.Value) driver.Value
+
+	converter driver.ValueConverter
+
+	// whether values include references, resolved by cursor
+	refs bool
+
+	// problems found while building rows, reported by Validate
+	problems []string
+	buildErr error
+
+	requireColumns bool
+	anyColumns     bool
 }
 
 func (r *rows) Columns() []string {
@@ -64,9 +176,19 @@ func (r *rows) Columns() []string {
 }
 
 func (r *rows) Close() error {
+	r.wipeBuffers()
 	return r.closeErr
 }
 
+// wipeBuffers invalidates values returned in reused buffers
+func (r *rows) wipeBuffers() {
+	for _, buf := range r.buffers {
+		for i := range buf {
+			buf[i] = 0
+		}
+	}
+}
+
 // advances to next row
 func (r *rows) Next(dest []driver.Value) error {
 	r.pos++
@@ -74,13 +196,149 @@ func (r *rows) Next(dest []driver.Value) error {
 		return io.EOF // per interface spec
 	}
 
+	r.wipeBuffers()
 	for i, col := range r.rows[r.pos-1] {
+		if c := r.column(i); c != nil {
+			var err error
+			if col, err = c.convert(col); err != nil {
+				return err
+			}
+		}
+
+		if fault, ok := r.faults[i]; ok {
+			col = fault(col)
+		}
+
 		dest[i] = col
+		if !r.reuse {
+			continue
+		}
+
+		var b []byte
+		switch v := col.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		default:
+			continue
+		}
+
+		if len(r.buffers) != len(r.cols) {
+			r.buffers = make([][]byte, len(r.cols))
+		}
+		r.buffers[i] = append(r.buffers[i][:0], b...)
+		dest[i] = r.buffers[i]
 	}
 
 	return r.nextErr[r.pos-1]
 }
 
+// queryRowRows records how rows returned
+// for a QueryRow expectation were consumed
+type queryRowRows struct {
+	driver.Rows
+	expected *ExpectedQuery
+}
+
+func (r *queryRowRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != io.EOF {
+		r.expected.Lock()
+		r.expected.rowsFetched++
+		r.expected.Unlock()
+	}
+	return err
+}
+
+func (r *queryRowRows) Close() error {
+	r.expected.Lock()
+	r.expected.rowsClosed = true
+	r.expected.Unlock()
+	return r.Rows.Close()
+}
+
+func (r *queryRowRows) ColumnTypeDatabaseTypeName(index int) string {
+	if rs, ok := r.Rows.(interface {
+		ColumnTypeDatabaseTypeName(int) string
+	}); ok {
+		return rs.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *queryRowRows) ColumnTypeLength(index int) (int64, bool) {
+	if rs, ok := r.Rows.(interface {
+		ColumnTypeLength(int) (int64, bool)
+	}); ok {
+		return rs.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *queryRowRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if rs, ok := r.Rows.(interface {
+		ColumnTypePrecisionScale(int) (int64, int64, bool)
+	}); ok {
+		return rs.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+func (r *queryRowRows) ColumnTypeNullable(index int) (bool, bool) {
+	if rs, ok := r.Rows.(interface {
+		ColumnTypeNullable(int) (bool, bool)
+	}); ok {
+		return rs.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *queryRowRows) ColumnTypeScanType(index int) reflect.Type {
+	if rs, ok := r.Rows.(interface {
+		ColumnTypeScanType(int) reflect.Type
+	}); ok {
+		return rs.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// resultSets returns several sets of rows
+// as multiple result sets of a single query
+type resultSets struct {
+	sets []driver.Rows
+	pos  int
+}
+
+func (r *resultSets) Columns() []string {
+	return r.sets[r.pos].Columns()
+}
+
+func (r *resultSets) Next(dest []driver.Value) error {
+	return r.sets[r.pos].Next(dest)
+}
+
+func (r *resultSets) Close() (err error) {
+	for _, set := range r.sets {
+		if e := set.Close(); err == nil {
+			err = e
+		}
+	}
+	return
+}
+
+func (r *resultSets) HasNextResultSet() bool {
+	return r.pos < len(r.sets)-1
+}
+
+func (r *resultSets) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.pos++
+	return nil
+}
+
 // NewRows allows Rows to be created from a
 // sql driver.Value slice or from the CSV string and
 // to be used as sql driver.Rows
@@ -88,23 +346,106 @@ func NewRows(columns []string) Rows {
 	return &rows{cols: columns, nextErr: make(map[int]error)}
 }
 
+// cursor returns a copy of rows positioned before the first row,
+// so that the same rows could be returned more than once
+func (r *rows) cursor() *rows {
+	cp := *r
+	cp.pos = 0
+	cp.buffers = nil
+	// rows added to the copy never overwrite the shared ones
+	cp.rows = r.rows[:len(r.rows):len(r.rows)]
+	if r.refs {
+		cp.rows = resolveRefs(r.rows)
+	}
+	cp.nextErr = nil
+	if len(r.nextErr) > 0 {
+		cp.nextErr = make(map[int]error, len(r.nextErr))
+		for row, err := range r.nextErr {
+			cp.nextErr[row] = err
+		}
+	}
+	return &cp
+}
+
+func (r *rows) ReuseBuffers() Rows {
+	r.reuse = true
+	return r
+}
+
+func (r *rows) TruncateColumn(column string, length int) Rows {
+	return r.injectFault(column, func(v driver.Value) driver.Value {
+		switch t := v.(type) {
+		case string:
+			if len(t) > length {
+				return t[:length]
+			}
+		case []byte:
+			if len(t) > length {
+				return t[:length]
+			}
+		}
+		return v
+	})
+}
+
+func (r *rows) OverflowColumn(column string) Rows {
+	return r.injectFault(column, func(v driver.Value) driver.Value {
+		switch v.(type) {
+		case int, int8, int16, int32, int64:
+			return int64(math.MaxInt64)
+		case uint, uint8, uint16, uint32, uint64:
+			return uint64(math.MaxUint64)
+		case float32, float64:
+			return math.MaxFloat64
+		}
+		return v
+	})
+}
+
+// injectFault sets a function, which corrupts values of the column
+func (r *rows) injectFault(column string, fault func(driver.Value) driver.Value) Rows {
+	for i, col := range r.cols {
+		if col == column {
+			if r.faults == nil {
+				r.faults = make(map[int]func(driver.Value) driver.Value)
+			}
+			r.faults[i] = fault
+			return r
+		}
+	}
+	panic(fmt.Sprintf("Expected column '%s' to be one of %v", column, r.cols))
+}
+
 func (r *rows) CloseError(err error) Rows {
 	r.closeErr = err
 	return r
 }
 
 func (r *rows) RowError(row int, err error) Rows {
+	if r.nextErr == nil {
+		r.nextErr = make(map[int]error)
+	}
 	r.nextErr[row] = err
 	return r
 }
 
 func (r *rows) AddRow(values ...driver.Value) Rows {
 	if len(values) != len(r.cols) {
-		panic("Expected number of values to match number of columns")
+		return r.buildError(fmt.Errorf("row %d has %d values, but there are %d columns", len(r.rows)+1, len(values), len(r.cols)))
 	}
 
 	row := make([]driver.Value, len(r.cols))
 	for i, v := range values {
+		if _, ok := v.(*Ref); ok {
+			r.refs = true
+		} else if valuer, ok := v.(driver.Valuer); ok {
+			converted, err := r.valueConverter().ConvertValue(valuer)
+			if err != nil {
+				r.convertError(len(r.rows), fmt.Errorf("row %d column '%s' value of %T failed to convert: %s", len(r.rows)+1, r.cols[i], v, err))
+			} else {
+				v = converted
+			}
+		}
 		row[i] = v
 	}
 
@@ -112,9 +453,88 @@ func (r *rows) AddRow(values ...driver.Value) Rows {
 	return r
 }
 
+func (r *rows) AddRowMap(values map[string]interface{}) Rows {
+	known := make(map[string]bool, len(r.cols))
+	for _, col := range r.cols {
+		known[col] = true
+	}
+	var unknown []string
+	for col := range values {
+		if !known[col] {
+			unknown = append(unknown, col)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return r.buildError(fmt.Errorf("row %d has values of unknown columns '%s'", len(r.rows)+1, strings.Join(unknown, "', '")))
+	}
+
+	row := make([]driver.Value, len(r.cols))
+	for i, col := range r.cols {
+		v, ok := values[col]
+		if !ok && r.requireColumns {
+			return r.buildError(fmt.Errorf("row %d has no value of column '%s'", len(r.rows)+1, col))
+		}
+		row[i] = v
+	}
+	return r.AddRow(row...)
+}
+
+func (r *rows) AllowAnyColumns() Rows {
+	r.anyColumns = true
+	return r
+}
+
+// columnsAllowed tells whether the columns are not validated
+func (r *rows) columnsAllowed() bool {
+	return r.anyColumns
+}
+
+func (r *rows) RequireColumns() Rows {
+	r.requireColumns = true
+	return r
+}
+
+// buildError records the error of a row, which could not be added,
+// it is returned by Err and by the query returning the rows
+func (r *rows) buildError(err error) Rows {
+	r.problems = append(r.problems, err.Error())
+	if r.buildErr == nil {
+		r.buildErr = err
+	}
+	return r
+}
+
+func (r *rows) Err() error {
+	return r.buildErr
+}
+
+func (r *rows) ValueConverter(converter driver.ValueConverter) Rows {
+	r.converter = converter
+	return r
+}
+
+// valueConverter returns the converter of driver.Valuer values
+func (r *rows) valueConverter() driver.ValueConverter {
+	if r.converter != nil {
+		return r.converter
+	}
+	return driver.DefaultParameterConverter
+}
+
+// convertError records the error of a value, which failed to convert,
+// it is returned for the row, unless the row has an error set already
+func (r *rows) convertError(row int, err error) {
+	r.problems = append(r.problems, err.Error())
+	if _, ok := r.nextErr[row]; !ok {
+		r.RowError(row, err)
+	}
+}
+
 func (r *rows) FromCSVString(s string) Rows {
 	res := strings.NewReader(strings.TrimSpace(s))
 	csvReader := csv.NewReader(res)
+	csvReader.FieldsPerRecord = -1 // rows of wrong length are reported by Validate
 
 	for {
 		res, err := csvReader.Read()
@@ -122,9 +542,13 @@ func (r *rows) FromCSVString(s string) Rows {
 			break
 		}
 
+		if len(res) != len(r.cols) {
+			r.problems = append(r.problems, fmt.Sprintf("csv row %d has %d values, but there are %d columns", len(r.rows)+1, len(res), len(r.cols)))
+		}
+
 		row := make([]driver.Value, len(r.cols))
-		for i, v := range res {
-			row[i] = CSVColumnParser(strings.TrimSpace(v))
+		for i := 0; i < len(res) && i < len(row); i++ {
+			row[i] = CSVColumnParser(strings.TrimSpace(res[i]))
 		}
 		r.rows = append(r.rows, row)
 	}
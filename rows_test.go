@@ -2,7 +2,9 @@ package sqlmock
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -246,3 +248,353 @@ func TestCSVRowParser(t *testing.T) {
 		t.Fatalf("expected col2 to be nil, but got [%T]:%+v", col2, col2)
 	}
 }
+
+func TestRowsReuseBuffersInvalidatesRawBytes(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rs := NewRows([]string{"id", "name"}).
+		AddRow(1, "alice").
+		AddRow(2, []byte("bob")).
+		ReuseBuffers()
+	mock.ExpectQuery("SELECT").WillReturnRows(rs)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying", err)
+	}
+	defer rows.Close()
+
+	var id int
+	var first, second sql.RawBytes
+	if !rows.Next() {
+		t.Fatal("expected first row")
+	}
+	if err = rows.Scan(&id, &first); err != nil {
+		t.Fatalf("error '%s' was not expected while scanning", err)
+	}
+	if string(first) != "alice" {
+		t.Errorf("expected raw bytes to be 'alice', but got '%s'", first)
+	}
+
+	if !rows.Next() {
+		t.Fatal("expected second row")
+	}
+	if err = rows.Scan(&id, &second); err != nil {
+		t.Fatalf("error '%s' was not expected while scanning", err)
+	}
+	if string(second) != "bob" {
+		t.Errorf("expected raw bytes to be 'bob', but got '%s'", second)
+	}
+
+	if string(first) == "alice" {
+		t.Errorf("expected raw bytes of the first row to be invalidated by Next, but it is still '%s'", first)
+	}
+}
+
+func TestRowsValueFaultInjection(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rs := NewRows([]string{"id", "name", "age"}).
+		AddRow(1, "alexander", 30).
+		TruncateColumn("name", 4).
+		OverflowColumn("age")
+	mock.ExpectQuery("SELECT").WillReturnRows(rs)
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+
+	var id int
+	var name string
+	var age int32
+	err = rows.Scan(&id, &name, &age)
+	if err == nil || !strings.Contains(err.Error(), "value out of range") {
+		t.Errorf("expected out of range error while scanning age, but got: %v", err)
+	}
+
+	var age64 int64
+	if err = rows.Scan(&id, &name, &age64); err != nil {
+		t.Errorf("error '%s' was not expected while scanning", err)
+	}
+
+	if name != "alex" {
+		t.Errorf("expected name to be truncated to 'alex', but got '%s'", name)
+	}
+}
+
+func TestRowsFaultInjectionUnknownColumn(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for unknown column")
+		}
+	}()
+	NewRows([]string{"id"}).TruncateColumn("name", 1)
+}
+
+func TestRowsMultipleResultSets(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	users := NewRows([]string{"id", "name"}).AddRow(1, "john").AddRow(2, "mark")
+	orders := NewRows([]string{"id"}).AddRow(7)
+	mock.ExpectQuery("SELECT").WillReturnRows(users, orders)
+
+	rs, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer rs.Close()
+
+	var names []string
+	for rs.Next() {
+		var id int
+		var name string
+		if err := rs.Scan(&id, &name); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		names = append(names, name)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected 2 users in the first result set, but got: %v", names)
+	}
+
+	if !rs.NextResultSet() {
+		t.Fatalf("expected the second result set, but there was none: %v", rs.Err())
+	}
+	var ids []int
+	for rs.Next() {
+		var id int
+		if err := rs.Scan(&id); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) != 1 || ids[0] != 7 {
+		t.Fatalf("expected order 7 in the second result set, but got: %v", ids)
+	}
+
+	if rs.NextResultSet() {
+		t.Fatal("expected no more result sets")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestRowSetsPerCall(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.RequireExpectations(true)
+
+	mock.ExpectQuery("SELECT count").
+		Times(3).
+		WillReturnRows(NewRows([]string{"count"}).AddRow(1), NewRows([]string{"count"}).AddRow(2)).
+		RowSetsPerCall()
+
+	for i, expected := range []int{1, 2, 2} {
+		if i == 2 {
+			if err := mock.ExpectationsWereMet(); err == nil {
+				t.Fatal("expected the query to be pending, until called 3 times")
+			}
+		}
+
+		var count int
+		if err := db.QueryRow("SELECT count").Scan(&count); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if count != expected {
+			t.Errorf("expected count %d on call %d, but got %d", expected, i+1, count)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+
+	if _, err := db.Query("SELECT count"); err == nil {
+		t.Error("expected an error, since the query was already called 3 times")
+	}
+}
+
+type money struct {
+	cents int64
+}
+
+func (m money) Value() (driver.Value, error) {
+	if m.cents < 0 {
+		return nil, fmt.Errorf("negative amount")
+	}
+	return fmt.Sprintf("%d.%02d", m.cents/100, m.cents%100), nil
+}
+
+func TestRowsConvertValuers(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rows := NewRows([]string{"id", "balance"}).
+		AddRow(1, money{1050}).
+		AddRow(2, money{-1})
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	if err := mock.Validate(); err == nil || !strings.Contains(err.Error(), "row 2 column 'balance' value of sqlmock.money failed to convert: negative amount") {
+		t.Errorf("expected validation to report the value, which failed to convert, but got: %v", err)
+	}
+
+	rs, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer rs.Close()
+
+	var balances []string
+	for rs.Next() {
+		var id int
+		var balance string
+		if err := rs.Scan(&id, &balance); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		balances = append(balances, balance)
+	}
+	if len(balances) != 1 || balances[0] != "10.50" {
+		t.Errorf("expected the converted balance 10.50, but got: %v", balances)
+	}
+	if err := rs.Err(); err == nil || !strings.Contains(err.Error(), "negative amount") {
+		t.Errorf("expected the conversion error for the second row, but got: %v", err)
+	}
+}
+
+func TestRowsArityErrorIsDeferred(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rows := NewRows([]string{"id", "name"}).
+		AddRow(1, "john").
+		AddRow(2).
+		AddRow(3, "mark", "extra")
+	var reporter RowsBuildReporter
+	if !As(rows, &reporter) {
+		t.Fatal("expected rows to report build errors")
+	}
+	if err := reporter.Err(); err == nil || err.Error() != "row 2 has 1 values, but there are 2 columns" {
+		t.Errorf("expected an error about the first row of wrong length, but got: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	_, err = db.Query("SELECT")
+	if err == nil || !strings.HasSuffix(err.Error(), "rows of the expectation failed to build: row 2 has 1 values, but there are 2 columns") {
+		t.Errorf("expected the build error when the expectation is matched, but got: %v", err)
+	}
+}
+
+func TestRowsAddRowMap(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rows := NewRows([]string{"id", "name", "email"})
+	var mapper RowsMapper
+	if !As(rows, &mapper) {
+		t.Fatal("expected rows to add rows of values keyed by column name")
+	}
+	mapper.AddRowMap(map[string]interface{}{"email": "john@example.com", "id": 1})
+	mapper.AddRowMap(map[string]interface{}{"name": "mark", "id": 2})
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	rs, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying", err)
+	}
+	defer rs.Close()
+
+	var got []string
+	for rs.Next() {
+		var id int
+		var name, email sql.NullString
+		if err := rs.Scan(&id, &name, &email); err != nil {
+			t.Fatalf("error '%s' was not expected while scanning", err)
+		}
+		got = append(got, fmt.Sprintf("%d %v %v", id, name, email))
+	}
+	if exp := "1 { false} {john@example.com true}, 2 {mark true} { false}"; strings.Join(got, ", ") != exp {
+		t.Errorf("expected rows '%s', but got '%s'", exp, strings.Join(got, ", "))
+	}
+}
+
+func TestRowsAddRowMapErrors(t *testing.T) {
+	t.Parallel()
+	var mapper RowsMapper
+	var reporter RowsBuildReporter
+	rows := NewRows([]string{"id", "name"})
+	if !As(rows, &mapper) || !As(rows, &reporter) {
+		t.Fatal("expected rows to add rows of values keyed by column name and report build errors")
+	}
+	mapper.AddRowMap(map[string]interface{}{"id": 1, "nmae": "john", "age": 30})
+	if err := reporter.Err(); err == nil || err.Error() != "row 1 has values of unknown columns 'age', 'nmae'" {
+		t.Errorf("expected an error about the unknown columns, but got: %v", err)
+	}
+
+	rows = NewRows([]string{"id", "name"})
+	As(rows, &mapper)
+	As(rows, &reporter)
+	mapper.RequireColumns()
+	mapper.AddRowMap(map[string]interface{}{"id": 1})
+	if err := reporter.Err(); err == nil || err.Error() != "row 1 has no value of column 'name'" {
+		t.Errorf("expected an error about the missing column, but got: %v", err)
+	}
+}
+
+func TestRowsExtensions(t *testing.T) {
+	t.Parallel()
+	rows := NewRows([]string{"id"})
+
+	var mapper RowsMapper
+	var loader RowsLoader
+	var converter RowsConverter
+	var reporter RowsBuildReporter
+	var allower RowsColumnsAllower
+	if !As(rows, &mapper) || !As(rows, &loader) || !As(rows, &converter) || !As(rows, &reporter) || !As(rows, &allower) {
+		t.Error("expected rows to implement all of the extensions")
+	}
+
+	// a wrapper of Rows is still rows, but without extensions
+	wrapped := struct{ Rows }{rows}
+	if As(wrapped, &mapper) {
+		t.Error("expected a wrapper of rows not to implement extensions")
+	}
+}
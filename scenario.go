@@ -0,0 +1,107 @@
+package sqlmock
+
+import "fmt"
+
+// Scenario scripts the behavior of the database in named phases, like
+// setup, steady state, failure and recovery, for testing long running
+// workers. Every phase queues its own expectations and the test
+// advances phases explicitly:
+//
+//	scenario := sqlmock.NewScenario(mock).
+//		Phase("steady", func(mock sqlmock.Sqlmock) {
+//			mock.ExpectQuery("SELECT (.+) FROM jobs").WillReturnRows(jobs)
+//		}).
+//		Phase("failure", func(mock sqlmock.Sqlmock) {
+//			mock.ExpectQuery("SELECT (.+) FROM jobs").WillReturnError(errors.New("server closed the connection"))
+//		})
+//	if err := scenario.Start(); err != nil {
+//		t.Fatal(err)
+//	}
+//	// run the worker
+//	if err := scenario.Advance(); err != nil {
+//		t.Fatal(err)
+//	}
+//
+// Scenario is not safe for concurrent use.
+type Scenario struct {
+	mock    Sqlmock
+	phases  []scenarioPhase
+	current int
+}
+
+type scenarioPhase struct {
+	name   string
+	expect func(mock Sqlmock)
+}
+
+// NewScenario creates a scenario of the mock without phases
+func NewScenario(mock Sqlmock) *Scenario {
+	return &Scenario{mock: mock, current: -1}
+}
+
+// Phase adds the named phase, which queues its expectations by expect
+func (s *Scenario) Phase(name string, expect func(mock Sqlmock)) *Scenario {
+	s.phases = append(s.phases, scenarioPhase{name: name, expect: expect})
+	return s
+}
+
+// Current returns the name of the current phase, empty until the
+// scenario is started
+func (s *Scenario) Current() string {
+	if s.current < 0 || s.current >= len(s.phases) {
+		return ""
+	}
+	return s.phases[s.current].name
+}
+
+// Start queues the expectations of the first phase
+func (s *Scenario) Start() error {
+	if s.current >= 0 {
+		return fmt.Errorf("scenario was already started, it is in phase '%s'", s.Current())
+	}
+	if len(s.phases) == 0 {
+		return fmt.Errorf("scenario has no phases")
+	}
+	return s.enter(0)
+}
+
+// Advance verifies that all expectations of the current phase were
+// met, discards them, including any other queued expectations, and
+// queues the expectations of the next phase
+func (s *Scenario) Advance() error {
+	if s.current < 0 {
+		return fmt.Errorf("scenario was not started")
+	}
+	if err := s.Verify(); err != nil {
+		return err
+	}
+	if s.current+1 >= len(s.phases) {
+		return fmt.Errorf("scenario has no phase after '%s'", s.Current())
+	}
+	return s.enter(s.current + 1)
+}
+
+// Verify verifies that all expectations of the current phase were met,
+// an error tells the phase
+func (s *Scenario) Verify() error {
+	if err := s.mock.ExpectationsWereMet(); err != nil {
+		return fmt.Errorf("phase '%s': %s", s.Current(), err)
+	}
+	return nil
+}
+
+// enter discards queued expectations and queues the ones of phase i
+func (s *Scenario) enter(i int) error {
+	c, ok := s.mock.(*sqlmock)
+	if !ok {
+		return fmt.Errorf("cannot run scenario on %T, only sqlmock created mocks are supported", s.mock)
+	}
+
+	c.Lock()
+	c.expected = nil
+	c.Unlock()
+
+	s.current = i
+	s.phases[i].expect(s.mock)
+	return nil
+}
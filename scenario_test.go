@@ -0,0 +1,47 @@
+package sqlmock
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScenarioPhases(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	scenario := NewScenario(mock).
+		Phase("steady", func(mock Sqlmock) {
+			mock.ExpectExec("UPDATE jobs").WillReturnResult(NewResult(0, 1))
+		}).
+		Phase("failure", func(mock Sqlmock) {
+			mock.ExpectExec("UPDATE jobs").WillReturnError(errors.New("server closed the connection"))
+		})
+	if err := scenario.Start(); err != nil {
+		t.Fatalf("an error '%s' was not expected when starting the scenario", err)
+	}
+
+	if err := scenario.Advance(); err == nil || err.Error() != "phase 'steady': there is a remaining expectation which was not matched: "+lockedString(mock.(*sqlmock).expected[0]) {
+		t.Errorf("expected an error about the unmet expectation of the steady phase, but got: %v", err)
+	}
+	if _, err := db.Exec("UPDATE jobs SET state = ?", "done"); err != nil {
+		t.Errorf("an error '%s' was not expected in the steady phase", err)
+	}
+	if err := scenario.Advance(); err != nil {
+		t.Fatalf("an error '%s' was not expected when advancing the scenario", err)
+	}
+	if scenario.Current() != "failure" {
+		t.Errorf("expected the failure phase, but got '%s'", scenario.Current())
+	}
+
+	if _, err := db.Exec("UPDATE jobs SET state = ?", "done"); err == nil {
+		t.Errorf("expected an error in the failure phase")
+	}
+	if err := scenario.Advance(); err == nil || err.Error() != "scenario has no phase after 'failure'" {
+		t.Errorf("expected an error about the last phase, but got: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package sqlmock
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (c *sqlmock) StrictColumns() {
+	c.Lock()
+	c.strictColumns = true
+	c.Unlock()
+}
+
+// columnChecks tells whether returned columns are checked against the
+// query and whether they are validated, see ValidateColumns
+func (c *sqlmock) columnChecks() (strict, validate bool) {
+	c.Lock()
+	defer c.Unlock()
+	return c.strictColumns, c.validateColumns
+}
+
+// checkColumns verifies that the columns selected by the stripped query
+// are the columns defined by the rows, unless it selects a wildcard
+func checkColumns(query string, columns []string) error {
+	selected, ok := selectedColumns(query)
+	if !ok {
+		return nil
+	}
+
+	same := len(selected) == len(columns)
+	for i := 0; same && i < len(selected); i++ {
+		same = selected[i] == "" || strings.EqualFold(selected[i], columns[i])
+	}
+	if same {
+		return nil
+	}
+
+	names := make([]string, len(selected))
+	for i, name := range selected {
+		names[i] = name
+		if name == "" {
+			names[i] = "?"
+		}
+	}
+	return fmt.Errorf("query '%s' selects columns [%s], but rows of the expectation define [%s]", query, strings.Join(names, ", "), strings.Join(columns, ", "))
+}
+
+// selectedColumns heuristically parses the names of columns selected
+// by the sql statement, an expression without alias has an empty name.
+// It does not parse statements other than SELECT, nor select lists
+// having a wildcard, whose columns depend on the schema.
+func selectedColumns(query string) ([]string, bool) {
+	list := strings.TrimSpace(query)
+	if !hasPrefixFold(list, "SELECT ") {
+		return nil, false
+	}
+	list = strings.TrimSpace(list[len("SELECT "):])
+	for _, modifier := range []string{"DISTINCT ", "ALL "} {
+		if hasPrefixFold(list, modifier) {
+			list = strings.TrimSpace(list[len(modifier):])
+		}
+	}
+	if hasPrefixFold(list, "ON ") || hasPrefixFold(list, "ON(") {
+		return nil, false // postgres DISTINCT ON expressions
+	}
+
+	var columns []string
+	for _, item := range selectItems(list) {
+		if item == "*" || strings.HasSuffix(item, ".*") {
+			return nil, false
+		}
+		columns = append(columns, selectedName(item))
+	}
+	return columns, len(columns) > 0
+}
+
+// selectItems splits the select list by commas, which are not within
+// parenthesis or quotes, up to the FROM clause
+func selectItems(list string) (items []string) {
+	var depth, start int
+	var quote byte
+	for i := 0; i < len(list); i++ {
+		ch := list[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(list[start:i]))
+			start = i + 1
+		case depth == 0 && (i == 0 || !isWordByte(list[i-1])) && isClauseAt(list[i:]):
+			return append(items, strings.TrimSpace(list[start:i]))
+		}
+	}
+	return append(items, strings.TrimSpace(list[start:]))
+}
+
+// isClauseAt tells whether a clause ending the select list starts at s
+func isClauseAt(s string) bool {
+	for _, kw := range []string{"FROM", "INTO", "WHERE", "UNION", "EXCEPT", "INTERSECT", "ORDER", "LIMIT", "GROUP"} {
+		if hasPrefixFold(s, kw) && (len(s) == len(kw) || !isWordByte(s[len(kw)])) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordByte(ch byte) bool {
+	return ch == '_' || ch == '$' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9'
+}
+
+// selectedName returns the name of the column selected by the item,
+// which is its alias or the name of the selected column
+func selectedName(item string) string {
+	unquote := strings.NewReplacer("\"", "", "`", "", "[", "", "]", "")
+	tokens := sqlTokenRe.FindAllString(item, -1)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	last := tokens[len(tokens)-1]
+	if last[0] == '\'' || last[0] >= '0' && last[0] <= '9' || strings.ContainsAny(last[:1], "(),;") {
+		return "" // a literal or an expression
+	}
+
+	switch {
+	case len(tokens) >= 2 && strings.EqualFold(tokens[len(tokens)-2], "AS"):
+		return unquote.Replace(last)
+	case len(tokens) == 1 && item == last:
+		// a column, possibly qualified by its table
+		if i := strings.LastIndex(last, "."); i >= 0 {
+			last = last[i+1:]
+		}
+		return unquote.Replace(last)
+	}
+
+	// an implicit alias follows the expression after whitespace
+	rest := strings.TrimSpace(strings.TrimSuffix(item, last))
+	if len(rest) < len(item)-len(last) && rest != "" {
+		if ch := rest[len(rest)-1]; ch == ')' || ch == '\'' || ch == '"' || ch == '`' || isWordByte(ch) {
+			return unquote.Replace(last)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectedColumns(t *testing.T) {
+	cases := []struct {
+		query   string
+		columns string
+		ok      bool
+	}{
+		{"SELECT id, name FROM users", "id,name", true},
+		{"SELECT u.id, u.\"Name\" FROM users u", "id,Name", true},
+		{"SELECT DISTINCT id FROM users", "id", true},
+		{"SELECT count(*) AS total, max(id) FROM users", "total,", true},
+		{"SELECT coalesce(name, 'none, really') label FROM users", "label", true},
+		{"SELECT id, (SELECT max(id) FROM orders) last_order FROM users WHERE id = ?", "id,last_order", true},
+		{"SELECT price * qty, 1 FROM items", ",", true},
+		{"SELECT * FROM users", "", false},
+		{"SELECT u.*, o.id FROM users u JOIN orders o ON o.user_id = u.id", "", false},
+		{"SELECT DISTINCT ON (id) id, name FROM users", "", false},
+		{"UPDATE users SET name = ?", "", false},
+	}
+
+	for _, c := range cases {
+		columns, ok := selectedColumns(c.query)
+		if ok != c.ok {
+			t.Errorf("expected query '%s' to be parsed: %t, but got: %t", c.query, c.ok, ok)
+			continue
+		}
+		if ok && strings.Join(columns, ",") != c.columns {
+			t.Errorf("expected query '%s' to select columns [%s], but got [%s]", c.query, c.columns, strings.Join(columns, ","))
+		}
+	}
+}
+
+func TestStrictColumns(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.StrictColumns()
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"ID", "name"}).AddRow(1, "bob"))
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id", "email"}).AddRow(1, "bob@example.com"))
+
+	rs, err := db.Query("SELECT id, name FROM users")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while querying", err)
+	} else {
+		rs.Close()
+	}
+
+	_, err = db.Query("SELECT id, name FROM users")
+	if err == nil || err.Error() != "query 'SELECT id, name FROM users' selects columns [id, name], but rows of the expectation define [id, email]" {
+		t.Errorf("expected an error about the drifted columns, but got: %v", err)
+	}
+}
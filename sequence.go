@@ -0,0 +1,142 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sync"
+)
+
+// Sequence simulates a database sequence or an auto increment
+// column, which generates identifiers of inserted rows. Results
+// and rows returned by its Result and Returning take the next
+// values of the sequence, whenever an expectation returning them
+// is matched, so that a chain of inserts gets consistent ids.
+type Sequence struct {
+	mu   sync.Mutex
+	name string
+	val  int64
+}
+
+func (c *sqlmock) Sequence(name string) *Sequence {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.sequences == nil {
+		c.sequences = make(map[string]*Sequence)
+	}
+	seq, ok := c.sequences[name]
+	if !ok {
+		seq = &Sequence{name: name}
+		c.sequences[name] = seq
+	}
+	return seq
+}
+
+// NextVal advances the sequence and returns its new value,
+// the first value is 1, unless the sequence was restarted
+func (s *Sequence) NextVal() int64 {
+	return s.advance(1)
+}
+
+// CurrVal returns the value most recently obtained from the
+// sequence, or 0 if none was obtained yet
+func (s *Sequence) CurrVal() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.val
+}
+
+// Restart makes the next value of the sequence to be start
+func (s *Sequence) Restart(start int64) {
+	s.mu.Lock()
+	s.val = start - 1
+	s.mu.Unlock()
+}
+
+// advance takes n values of the sequence and returns the first one
+func (s *Sequence) advance(n int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	first := s.val + 1
+	s.val += n
+	return first
+}
+
+func (s *Sequence) String() string {
+	return fmt.Sprintf("sequence '%s'", s.name)
+}
+
+// Result returns a result, which takes the last insert id from the
+// sequence for every matched exec. The sequence is advanced by the
+// number of rows affected and the id of the first one is returned,
+// the way MySQL does for multiple row inserts:
+//
+//	users := mock.Sequence("users_id")
+//	mock.ExpectExec("INSERT INTO users").WillReturnResult(users.Result(1)).Times(2)
+func (s *Sequence) Result(rowsAffected int64) driver.Result {
+	return &sequenceResult{seq: s, rowsAffected: rowsAffected}
+}
+
+// Returning returns rows, which values of the column are replaced by
+// the next values of the sequence for every matched query, so that an
+// INSERT ... RETURNING statement gets the generated ids:
+//
+//	users := mock.Sequence("users_id")
+//	mock.ExpectQuery("INSERT INTO users").
+//		WillReturnRows(users.Returning(sqlmock.NewRows([]string{"id", "name"}).AddRow(nil, "bob"), "id"))
+func (s *Sequence) Returning(rs Rows, column string) driver.Rows {
+	r, ok := rs.(*rows)
+	if !ok {
+		panic(fmt.Sprintf("Expected rows created by NewRows, but got %T", rs))
+	}
+	for i, col := range r.cols {
+		if col == column {
+			return &returningRows{rows: r, seq: s, column: i}
+		}
+	}
+	panic(fmt.Sprintf("Expected column '%s' to be one of %v", column, r.cols))
+}
+
+// sequenceResult generates a result for every matched exec
+type sequenceResult struct {
+	seq          *Sequence
+	rowsAffected int64
+}
+
+// LastInsertId returns the current value of the sequence, the
+// result is generated, when it is returned by the mock
+func (r *sequenceResult) LastInsertId() (int64, error) {
+	return r.seq.CurrVal(), nil
+}
+
+func (r *sequenceResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// next generates the result of a matched exec
+func (r *sequenceResult) next() driver.Result {
+	n := r.rowsAffected
+	if n < 1 {
+		n = 1
+	}
+	return NewResult(r.seq.advance(n), r.rowsAffected)
+}
+
+// returningRows generates rows for every matched query
+type returningRows struct {
+	*rows
+	seq    *Sequence
+	column int
+}
+
+// next generates the rows of a matched query, taking
+// a value of the sequence for each of them
+func (r *returningRows) next() *rows {
+	cp := r.rows.cursor()
+	cp.rows = resolveRefs(r.rows.rows)
+	for _, row := range cp.rows {
+		row[r.column] = r.seq.NextVal()
+	}
+	return cp
+}
@@ -0,0 +1,61 @@
+package sqlmock
+
+import (
+	"testing"
+)
+
+func TestSequenceResults(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	users := mock.Sequence("users_id")
+	if mock.Sequence("users_id") != users {
+		t.Errorf("expected the same sequence to be returned by name")
+	}
+	users.Restart(10)
+
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(users.Result(1)).Times(2)
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(users.Result(3))
+	mock.ExpectQuery("INSERT INTO users").
+		WillReturnRows(users.Returning(NewRows([]string{"id", "name"}).AddRow(nil, "bob").AddRow(nil, "ann"), "id"))
+
+	for _, expected := range []int64{10, 11, 12} {
+		res, err := db.Exec("INSERT INTO users(name) VALUES(?)", "bob")
+		if err != nil {
+			t.Fatalf("error '%s' was not expected while inserting", err)
+		}
+		if id, _ := res.LastInsertId(); id != expected {
+			t.Errorf("expected last insert id to be %d, but got %d", expected, id)
+		}
+	}
+
+	rs, err := db.Query("INSERT INTO users(name) VALUES(?), (?) RETURNING id, name", "bob", "ann")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while inserting", err)
+	}
+	defer rs.Close()
+
+	var ids []int64
+	for rs.Next() {
+		var id int64
+		var name string
+		if err := rs.Scan(&id, &name); err != nil {
+			t.Errorf("error '%s' was not expected while scanning", err)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) != 2 || ids[0] != 15 || ids[1] != 16 {
+		t.Errorf("expected returned ids to continue the sequence, but got %v", ids)
+	}
+	if users.CurrVal() != 16 {
+		t.Errorf("expected current value of the sequence to be 16, but got %d", users.CurrVal())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
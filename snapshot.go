@@ -0,0 +1,61 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+func (c *sqlmock) RecordArgs() {
+	c.Lock()
+	c.recording = true
+	c.Unlock()
+}
+
+func (c *sqlmock) ArgsSnapshot() string {
+	c.Lock()
+	defer c.Unlock()
+
+	var snapshot string
+	for _, ev := range c.recorded {
+		kind := "query"
+		if ev.Kind == ExecMatched {
+			kind = "exec"
+		}
+		snapshot += kind + " " + ev.Query + "\n"
+		for i, arg := range ev.Args {
+			if c.redact.redacts(i, arg) {
+				snapshot += fmt.Sprintf("  %d: %s\n", i, redacted)
+				continue
+			}
+			snapshot += fmt.Sprintf("  %d: %s\n", i, formatArg(arg))
+		}
+	}
+	return snapshot
+}
+
+// record keeps the event of a matched call for the snapshot of
+// arguments, if recording, must be called under the mock lock
+func (c *sqlmock) record(ev Event) {
+	if c.recording && (ev.Kind == QueryMatched || ev.Kind == ExecMatched) {
+		c.recorded = append(c.recorded, ev)
+	}
+}
+
+// formatArg renders the argument in a stable and readable
+// form, which does not depend on the local time zone
+func formatArg(arg driver.Value) string {
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []byte:
+		return fmt.Sprintf("[]byte(%q)", v)
+	case time.Time:
+		return "time(" + v.UTC().Format(time.RFC3339Nano) + ")"
+	case bool:
+		return fmt.Sprintf("%t", v)
+	}
+	return fmt.Sprintf("%T(%v)", arg, arg)
+}
@@ -0,0 +1,48 @@
+package sqlmock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArgsSnapshot(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(NewResult(1, 1))
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(NewRows([]string{"name"}))
+
+	if _, err := db.Exec("INSERT INTO users (id) VALUES (?)", 0); err != nil {
+		t.Fatalf("error '%s' was not expected while inserting a user", err)
+	}
+
+	mock.RecordArgs()
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("EET", 2*60*60))
+	if _, err := db.Exec("INSERT INTO users (name, avatar, created, admin, score, note) VALUES (?, ?, ?, ?, ?, ?)",
+		"john \"jj\"", []byte{0x1, 'a'}, created, true, 1.5, nil); err != nil {
+		t.Fatalf("error '%s' was not expected while inserting a user", err)
+	}
+	rs, err := db.Query("SELECT name FROM users WHERE id = ?", 5)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while selecting users", err)
+	}
+	rs.Close()
+
+	expected := `exec INSERT INTO users (name, avatar, created, admin, score, note) VALUES (?, ?, ?, ?, ?, ?)
+  0: "john \"jj\""
+  1: []byte("\x01a")
+  2: time(2020-01-02T01:04:05Z)
+  3: true
+  4: float64(1.5)
+  5: NULL
+query SELECT name FROM users WHERE id = ?
+  0: int64(5)
+`
+	if snapshot := mock.ArgsSnapshot(); snapshot != expected {
+		t.Errorf("expected args snapshot:\n%s\nbut got:\n%s", expected, snapshot)
+	}
+}
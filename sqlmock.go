@@ -12,17 +12,28 @@ are also supported.
 package sqlmock
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
-// Sqlmock interface serves to create expectations
-// for any kind of database action in order to mock
-// and test real database behavior.
-type Sqlmock interface {
+// SqlmockCommon is the core of Sqlmock interface, which
+// serves to create expectations for any kind of database
+// action in order to mock and test real database behavior.
+//
+// It is never extended, new capabilities of the mock are
+// added as extension interfaces instead, so that types
+// implementing or wrapping it do not break. Extensions
+// could be asserted with As.
+type SqlmockCommon interface {
 
 	// ExpectClose queues an expectation for this database
 	// action to be triggered. the *ExpectedClose allows
@@ -55,7 +66,12 @@ type Sqlmock interface {
 	ExpectBegin() *ExpectedBegin
 
 	// ExpectCommit expects *sql.Tx.Commit to be called.
-	// the *ExpectedCommit allows to mock database response
+	// the *ExpectedCommit allows to mock database response.
+	//
+	// Note that a Rollback deferred after a successful Commit,
+	// as in the usual defer tx.Rollback() pattern, needs no
+	// expectation: database/sql returns sql.ErrTxDone without
+	// calling the driver.
 	ExpectCommit() *ExpectedCommit
 
 	// ExpectRollback expects *sql.Tx.Rollback to be called.
@@ -69,18 +85,799 @@ type Sqlmock interface {
 	// to parallelize your query executation, that option may
 	// be handy.
 	MatchExpectationsInOrder(bool)
+}
+
+// QueryRowExpecter is an extension of SqlmockCommon, which
+// expects QueryRow() calls
+type QueryRowExpecter interface {
+
+	// ExpectQueryRow expects QueryRow() to be called with sql query
+	// which match sqlRegexStr given regexp. In addition to ExpectQuery
+	// it verifies that at most one row was fetched from the result
+	// and that the result was closed, as QueryRow does.
+	ExpectQueryRow(sqlRegexStr string) *ExpectedQuery
+}
+
+// FailureExpecter is an extension of SqlmockCommon, which
+// expects common failure scenarios
+type FailureExpecter interface {
+
+	// ExpectExecFailingTx expects Exec() to be called with sql query
+	// which match sqlRegexStr given regexp and to fail with err,
+	// followed by *sql.Tx.Rollback, which is the usual way how
+	// a failing statement is handled within a transaction.
+	// the *ExpectedExec allows to mock the arguments.
+	ExpectExecFailingTx(sqlRegexStr string, err error) *ExpectedExec
+
+	// ExpectDeadlock expects two Exec() calls, which match the given
+	// sql regexps and are made concurrently by different transactions,
+	// each waiting for a lock held by the other one. Whichever call
+	// comes first blocks until the conflicting one arrives. Then the
+	// deadlock is detected, the latter call fails with ErrDeadlock and
+	// the former one proceeds as expected. If the conflicting call
+	// does not arrive within a second, the waiting one fails instead
+	// of blocking the test.
+	// the *ExpectedExec pair allows to mock database response for both
+	// statements.
+	ExpectDeadlock(firstSQLRegexStr, secondSQLRegexStr string) (*ExpectedExec, *ExpectedExec)
+}
+
+// CancelExpecter is an extension of SqlmockCommon, which expects
+// calls to be cancelled by their context
+type CancelExpecter interface {
+
+	// ExpectCancel expects the context of a Query() or Exec() call to
+	// be done while the call is delayed, by WillDelayFor, WillDelayBy,
+	// WillTimeoutAfter or a latency profile. Then the call fails with the error of the
+	// context, as drivers do after sending a cancel request, like KILL
+	// QUERY of mysql, to the server. A cancellation, which is not
+	// expected, fails the call, if expectations are required.
+	ExpectCancel() *ExpectedCancel
+}
+
+// QueryIgnorer is an extension of SqlmockCommon, which answers
+// queries irrelevant to a test without expectations
+type QueryIgnorer interface {
+
+	// IgnoreQueries makes Query(), Exec() and Prepare() calls with sql
+	// query, which match any of sqlRegexStr given regexps, to be answered
+	// without matching any expectation. Such calls are not verified and
+	// do not take part in ordering.
+	// the *IgnoredQueries allows to mock database response.
+	IgnoreQueries(sqlRegexStr ...string) *IgnoredQueries
+
+	// WithServerVersion makes the mock to answer the version and
+	// capability detection queries, which ORMs and drivers of the
+	// given dialect issue on startup, as a server of the given
+	// version would, for example WithServerVersion(MySQL, "8.0.32").
+	// Such queries are ignored, as with IgnoreQueries.
+	WithServerVersion(dialect Dialect, version string)
+}
+
+// MatchConfigurer is an extension of SqlmockCommon, which
+// configures how calls are matched to expectations
+type MatchConfigurer interface {
+
+	// MatchExpectationsBy sets the policy used to choose an
+	// expectation when several pending ones match the same
+	// call. It has no effect when expectations are matched
+	// in order.
+	//
+	// By default it is set to - MatchFirst.
+	MatchExpectationsBy(MatchPolicy)
 
 	RequireExpectations(bool)
+
+	// AllowTables restricts Query(), Exec() and Prepare() calls to
+	// sql queries, which reference only the given tables. A call
+	// referencing any other table fails, regardless of expectations.
+	// Tables are parsed from the query heuristically and compared
+	// case insensitively, a schema qualified table is allowed by
+	// its unqualified name as well.
+	//
+	// By default all tables are allowed.
+	AllowTables(tables ...string)
+}
+
+// ArgsNormalizer is an extension of SqlmockCommon, which
+// normalizes arguments before they are compared
+type ArgsNormalizer interface {
+
+	// NormalizeArgs registers normalizer for arguments of the same
+	// type as example. Both expected and actual arguments are
+	// normalized before comparison, so that domain specific equality,
+	// like of decimals with trailing zeros, is encoded once instead
+	// of in every argument matcher.
+	NormalizeArgs(example driver.Value, normalizer ArgNormalizer)
+}
+
+// ClauseEnforcer is an extension of SqlmockCommon, which
+// requires clauses of filtering statements
+type ClauseEnforcer interface {
+
+	// RequireClause makes every SELECT, UPDATE and DELETE statement to
+	// fail, unless the given sql regexp matches it, regardless of
+	// expectations. It enforces cross-cutting predicates, like a
+	// tenant_id condition of every statement in a multi-tenant schema.
+	// May be called several times to require several clauses.
+	// Queries ignored by IgnoreQueries or WithServerVersion are not
+	// checked.
+	RequireClause(sqlRegexStr string)
+}
+
+// RawQueryMatcher is an extension of SqlmockCommon, which
+// matches queries without normalizing them
+type RawQueryMatcher interface {
+
+	// WithoutQueryNormalization makes queries to be matched exactly
+	// as given to the driver, without collapsing whitespace, for
+	// drivers which pass already parameterized or protocol specific
+	// strings, which stripping would corrupt.
+	WithoutQueryNormalization()
+}
+
+// ColumnsChecker is an extension of SqlmockCommon, which
+// checks selected columns against returned rows
+type ColumnsChecker interface {
+
+	// StrictColumns makes a SELECT query to fail, unless the columns
+	// of its select list, parsed from the query heuristically, are the
+	// columns of the rows returned by the matched expectation, in the
+	// same order. So that query text and fixtures could not drift
+	// apart. Aliases are compared case insensitively, expressions
+	// without alias match any column and select lists having a
+	// wildcard are not checked.
+	StrictColumns()
+}
+
+// WarningsCollector is an extension of SqlmockCommon, which
+// collects warnings about tolerated calls
+type WarningsCollector interface {
+
+	// Warnings returns non-fatal issues noticed by the mock, in the
+	// order they happened, like calls tolerated without expectation,
+	// since expectations are not required, or calls matched by a
+	// reusable expectation as a fallback, while the next expectation
+	// in order did not match. So that a suite could print them.
+	Warnings() []string
+
+	// WarningsAsErrors promotes warnings, see Warnings, to errors
+	// reported by ExpectationsWereMet, so that a suite could
+	// tolerate nothing, for example when run with a flag.
+	//
+	// By default warnings are not reported as errors.
+	WarningsAsErrors(bool)
+}
+
+// OrderScoper is an extension of SqlmockCommon, which
+// orders only some kinds of expectations
+type OrderScoper interface {
+
+	// MatchInOrderOnly matches expectations of the given scope in the
+	// order they were set, relative to each other, while all the other
+	// expectations are not matched in order. For example statements
+	// may be required to be ordered, while transaction markers may be
+	// interleaved by a connection pool:
+	//
+	//	mock.MatchInOrderOnly(sqlmock.OrderStatements)
+	//
+	// Expectations of Close and Connect are never ordered by it.
+	// MatchExpectationsInOrder overrides the scope.
+	MatchInOrderOnly(scope OrderScope)
+}
+
+// Rewriter is an extension of SqlmockCommon, which
+// rewrites calls before they are matched
+type Rewriter interface {
+
+	// RewriteQueries rewrites the query and arguments of every
+	// Query(), Exec() and Prepare() call, before they are matched to
+	// expectations, by the rewriters in the order they were added.
+	// So that expectations could be written against the sql of the
+	// application, while the calls pass a driver middleware, which
+	// adds hints or renames tables, like in production.
+	RewriteQueries(rewriters ...QueryRewriter)
+}
+
+// TransactionEnforcer is an extension of SqlmockCommon, which
+// requires writes to be made in transactions
+type TransactionEnforcer interface {
+
+	// RequireTransactions makes an INSERT, UPDATE, DELETE, MERGE or
+	// TRUNCATE statement to fail, unless a transaction is in progress,
+	// regardless of expectations, so that all writes could be enforced
+	// to go through Begin and Commit. Since connections share the mock,
+	// a transaction in progress on any of them allows writes.
+	RequireTransactions()
+}
+
+// Simulator is an extension of SqlmockCommon, which simulates
+// time dependent behavior of a database server
+type Simulator interface {
+
+	// Clock returns the simulated clock of this mock, which
+	// is used to measure time dependent database behavior.
+	// It only moves when advanced.
+	Clock() *Clock
+
+	// IdleInTransactionTimeout simulates the server terminating
+	// a session, which stays idle in transaction for longer than
+	// the given timeout, measured on the simulated Clock. Once it
+	// happens, all the following statements of that transaction,
+	// including Commit and Rollback, fail with
+	// ErrIdleInTransactionTimeout.
+	//
+	// By default the timeout is not set.
+	IdleInTransactionTimeout(time.Duration)
+
+	// UseLatencyProfile delays every matched Query() and Exec() call
+	// by a duration drawn from the profile, unless the expectation
+	// specifies its own delay.
+	UseLatencyProfile(*LatencyProfile)
+}
+
+// LimitSimulator is an extension of SqlmockCommon, which
+// simulates protocol limits of a database server
+type LimitSimulator interface {
+
+	// LimitParams makes Query() and Exec() calls with more than max
+	// bound parameters to fail, the way postgres does for more than
+	// PostgresMaxParams, so that batching code learns about the
+	// limit in tests.
+	//
+	// By default the number of parameters is not limited.
+	LimitParams(max int)
+
+	// LimitPacketSize makes Query() and Exec() calls to fail, when
+	// the query and its arguments take more than max bytes, the way
+	// MySQL does for packets bigger than max_allowed_packet, which is
+	// MySQLMaxAllowedPacket by default. The size is estimated by
+	// the length of strings and byte slices, and 8 bytes for numbers.
+	//
+	// By default the packet size is not limited.
+	LimitPacketSize(max int)
+}
+
+// LifetimeSimulator is an extension of SqlmockCommon, which
+// simulates the limited lifetime of connections
+type LifetimeSimulator interface {
+
+	// ConnMaxLifetime simulates sql.DB.SetConnMaxLifetime on the
+	// simulated Clock. Once the connection outlives d, the next call
+	// outside of transaction fails with driver.ErrBadConn, so that
+	// database/sql closes the connection and retries the call on a
+	// new one. The close is matched by ExpectClose and the new
+	// connection by ExpectConnect. The lifetime is measured since
+	// the latest connection was opened, which suits a pool limited
+	// to a single connection by sql.DB.SetMaxOpenConns.
+	//
+	// By default connections never expire.
+	ConnMaxLifetime(d time.Duration)
+
+	// ExpectConnect expects a connection to be opened again, after
+	// it expired due to ConnMaxLifetime.
+	ExpectConnect() *ExpectedConnect
+}
+
+// SequenceSimulator is an extension of SqlmockCommon, which
+// simulates database sequences
+type SequenceSimulator interface {
+
+	// Sequence returns the named sequence of this mock, which is
+	// created on first use. Its Result and Returning feed the ids
+	// generated by inserts to Exec() and Query() expectations.
+	Sequence(name string) *Sequence
+}
+
+// ReplicationSimulator is an extension of SqlmockCommon, which
+// simulates lagging read replicas
+type ReplicationSimulator interface {
+
+	// ReplicationLag simulates replication of the mock created by
+	// NewWithReplicas, so that tables written by Exec() calls on the
+	// primary handle are visible to queries on replica handles only
+	// after d on the simulated Clock. Until then such queries return
+	// the rows set by WillReturnStaleRows, or no rows by default, to
+	// test read-your-writes fallbacks.
+	//
+	// By default replicas do not lag.
+	ReplicationLag(d time.Duration)
+}
+
+// ThrottleSimulator is an extension of SqlmockCommon, which
+// simulates a limited number of connections
+type ThrottleSimulator interface {
+
+	// LimitInFlight simulates a saturated connection pool, so that
+	// at most max Query() and Exec() calls are in flight at a time,
+	// see WillDelayFor. Extra calls wait for a call to finish, for
+	// up to timeout on the simulated Clock, then fail with
+	// ErrPoolTimeout. If timeout is not positive, they wait as long
+	// as it takes.
+	//
+	// By default calls in flight are not limited.
+	LimitInFlight(max int, timeout time.Duration)
+}
+
+// RowsTransformer is an extension of SqlmockCommon, which
+// transforms the rows returned by queries
+type RowsTransformer interface {
+
+	// UseRowsMiddleware appends middleware, which is applied to
+	// the Rows returned by every matched query expectation,
+	// in the order they were added.
+	UseRowsMiddleware(...RowsMiddleware)
+}
+
+// Validator is an extension of SqlmockCommon, which checks
+// queued expectations for mistakes
+type Validator interface {
+
+	// Validate statically checks all queued expectations for common
+	// mistakes, like a sql regexp, which may never match a stripped
+	// query, arguments expected for a sql regexp matching no
+	// placeholders, or rows having a different number of values than
+	// columns. All problems found are reported by the error, so it may
+	// be called before a test body runs.
+	Validate() error
+}
+
+// ColumnsValidator is an extension of SqlmockCommon, which
+// validates columns of queued rows
+type ColumnsValidator interface {
+
+	// ValidateColumns rejects rows of expectations, which have no
+	// columns or duplicate column names, unless the rows allow it by
+	// AllowAnyColumns. They are reported by Validate and fail the
+	// query matching the expectation, instead of a confusing Scan
+	// failure later in a test.
+	ValidateColumns()
+}
+
+// DuplicatesMerger is an extension of SqlmockCommon, which
+// merges duplicate expectations
+type DuplicatesMerger interface {
+
+	// MergeDuplicates merges Query() and Exec() expectations, which
+	// were not called yet and match the same sql regexp, arguments and
+	// handle, into the first of them, expected to be called as many
+	// times as all of them, to keep large generated expectation sets
+	// manageable. In order, only consecutive duplicates are merged.
+	// It returns the number of expectations merged away, while an
+	// error lists duplicates, which return differently, and were left
+	// as they are.
+	MergeDuplicates() (int, error)
+}
+
+// CustomExpecter is an extension of SqlmockCommon, which
+// queues expectations implemented outside of sqlmock
+type CustomExpecter interface {
+
+	// ExpectCustom queues the expectation of a driver specific
+	// statement of the kind, registered by RegisterExpectationKind.
+	ExpectCustom(kind string, e CustomExpectation)
+}
+
+// StepExpecter is an extension of SqlmockCommon, which groups
+// expectations by the logical steps of a test
+type StepExpecter interface {
+
+	// Step queues the expectations, which expect queues, as the named
+	// step of a test, like "create user". An expectation, which was
+	// not met, tells the step it belongs to, so that a failure points
+	// at the step rather than at a query.
+	Step(name string, expect func())
+
+	// StrictSteps makes a call, which matches an expectation of a
+	// step, to fail, while any expectation of an earlier step was not
+	// met, so that steps complete before calls of the next one begin.
+	StrictSteps()
+}
+
+// NamedExpecter is an extension of SqlmockCommon, which
+// expects queries by their names, as generated by sqlc
+type NamedExpecter interface {
+
+	// NameQueries registers sql queries by the names, which their
+	// sqlc headers give, like "-- name: GetUser :one". Each of the
+	// sources may be a query constant generated by sqlc or a whole
+	// file of annotated queries, which sqlc generates from.
+	// It panics if a source has no named query.
+	NameQueries(sources ...string)
+
+	// ExpectNamed expects Query() or QueryRow() to be called with
+	// the sql query of the given name, which returns rows, like
+	// of :one or :many commands, so that tests do not depend on sql
+	// text, which sqlc may regenerate.
+	// the *ExpectedQuery allows to mock database response.
+	ExpectNamed(name string) *ExpectedQuery
+
+	// ExpectNamedExec expects Exec() to be called with the sql query
+	// of the given name, which returns no rows, like of :exec or
+	// :execrows commands.
+	// the *ExpectedExec allows to mock database response.
+	ExpectNamedExec(name string) *ExpectedExec
+}
+
+// ArgRedactor is an extension of SqlmockCommon, which masks
+// sensitive arguments in the output of the mock
+type ArgRedactor interface {
+
+	// RedactArgs masks values of arguments, which any of the rules
+	// tells to be sensitive, like passwords or personal data of
+	// fixtures, in errors, warnings and string representations of
+	// expectations, as well as in ArgsSnapshot and reports, so that
+	// they do not leak to CI logs. Arguments are still matched by
+	// their values and subscribers receive events with them as given.
+	RedactArgs(rules ...RedactionRule)
+}
+
+// FluentExpecter is an extension of SqlmockCommon, which
+// queues expectations in the style of gomock
+type FluentExpecter interface {
+
+	// EXPECT returns a recorder of expected calls, which mirrors
+	// the gomock ergonomics, for teams used to that style. The
+	// expectations are the same as queued by SqlmockCommon.
+	EXPECT() *Recorder
+}
+
+// Inspector is an extension of SqlmockCommon, which
+// reports how the mock was used
+type Inspector interface {
+
+	// PeakInFlight returns the highest number of Query() and
+	// Exec() calls, which were executing simultaneously. Calls
+	// overlap when they are delayed, see WillDelayFor, so that
+	// connection pool sizing or semaphores could be asserted.
+	PeakInFlight() int
+}
+
+// EventSubscriber is an extension of SqlmockCommon, which
+// streams events of calls
+type EventSubscriber interface {
+
+	// Subscribe returns a channel, which receives an Event for
+	// every Query(), Exec() and transaction related call, when
+	// it starts and when it is matched or fails. An event is
+	// dropped if the channel buffer of the given size is full,
+	// so that the mock never blocks. The channel is closed once
+	// the mock database is closed.
+	Subscribe(buffer int) <-chan Event
+}
+
+// PrepareCounter is an extension of SqlmockCommon, which
+// counts prepared statements
+type PrepareCounter interface {
+
+	// PrepareCounts returns how many times Prepare() was called
+	// per query, which is stripped of redundant whitespace.
+	PrepareCounts() map[string]int
+
+	// PreparedAtMost returns an error listing queries, which were
+	// prepared more than n times, so that a statement cache could
+	// be asserted to prevent redundant prepares.
+	PreparedAtMost(n int) error
+}
+
+// ArgsRecorder is an extension of SqlmockCommon, which
+// records arguments of matched calls
+type ArgsRecorder interface {
+
+	// RecordArgs starts recording arguments of matched Query() and
+	// Exec() calls for ArgsSnapshot.
+	RecordArgs()
+
+	// ArgsSnapshot renders arguments of calls matched since RecordArgs
+	// in a stable and human readable form, a line for every argument,
+	// NULL for nil, quoted strings and []byte values and times in UTC.
+	// It is meant to be compared with a golden file, so that changes
+	// of arguments are reviewed as diffs rather than failing matchers.
+	ArgsSnapshot() string
+}
+
+// LatencyReporter is an extension of SqlmockCommon, which
+// summarizes latencies of calls
+type LatencyReporter interface {
+
+	// RecordLatencies starts recording how long Query() and Exec()
+	// calls took for Latencies.
+	RecordLatencies()
+
+	// Latencies summarizes how long Query() and Exec() calls made
+	// since RecordLatencies took, including simulated delays, by
+	// query fingerprint, so that tests stubbing the database could
+	// still assert, for example, that a cache saved most of the slow
+	// queries.
+	Latencies() []LatencySummary
+}
+
+// Annotator is an extension of SqlmockCommon, which
+// extracts annotations of queries
+type Annotator interface {
+
+	// ExtractAnnotations extracts annotations of every Query() and
+	// Exec() call by the given extractors, like SQLCommenter. They
+	// are set on events and kept in the history of AnnotatedCalls,
+	// so that a query attribution middleware could be verified.
+	ExtractAnnotations(extractors ...AnnotationExtractor)
+
+	// AnnotatedCalls returns Query() and Exec() calls made since
+	// ExtractAnnotations, with their annotations.
+	AnnotatedCalls() []AnnotatedCall
+
+	// AllAnnotated returns an error listing calls, made since
+	// ExtractAnnotations, which lack any of the annotation keys.
+	AllAnnotated(keys ...string) error
+}
+
+// StatementCounter is an extension of SqlmockCommon, which
+// counts statements by kind
+type StatementCounter interface {
+
+	// StatementCounts returns the number of Query() and Exec() calls,
+	// which matched an expectation, by the kind of their statement.
+	// Tolerated unexpected calls and ignored queries are not counted.
+	StatementCounts() map[StatementKind]int
+
+	// StatementsWere returns an error, unless the number of Query()
+	// and Exec() calls of every given statement kind, which matched an
+	// expectation, is as expected, for example exactly one UPDATE and no DDL:
+	//
+	//	mock.StatementsWere(map[sqlmock.StatementKind]int{sqlmock.StatementUpdate: 1, sqlmock.StatementDDL: 0})
+	StatementsWere(expected map[StatementKind]int) error
+}
+
+// Reporter is an extension of SqlmockCommon, which
+// summarizes how expectations were met
+type Reporter interface {
+
+	// Report summarizes how expectations were met, including the
+	// number of calls, which did not match any, in a machine readable
+	// form, so that CI tooling could aggregate results of test reruns
+	// to find the expectations, which are most often unmet.
+	Report() Report
+}
+
+// PoolInspector is an extension of SqlmockCommon, which
+// reports connections of the mock
+type PoolInspector interface {
+
+	// PoolStats returns the connections of the mock, which are open,
+	// in use and idle, as database/sql would report them by Stats.
+	// A connection is in use by a Query() or Exec() call in flight,
+	// or by a transaction in progress.
+	PoolStats() PoolStats
+
+	// RecordPoolStats starts recording the history of PoolStats,
+	// sampled on the simulated Clock whenever they change, so that
+	// code tuning the pool by observed stats could be tested.
+	RecordPoolStats()
+
+	// PoolHistory returns the samples of PoolStats recorded since
+	// RecordPoolStats.
+	PoolHistory() []PoolSample
+
+	// PoolStayedWithin returns an error describing the first sample
+	// recorded since RecordPoolStats, which had more than maxOpen
+	// connections open or more than maxInUse in use.
+	PoolStayedWithin(maxOpen, maxInUse int) error
+}
+
+// Sqlmock interface serves to create expectations
+// for any kind of database action in order to mock
+// and test real database behavior. It combines the
+// SqlmockCommon with all its extensions.
+type Sqlmock interface {
+	SqlmockCommon
+	QueryRowExpecter
+	FailureExpecter
+	CancelExpecter
+	QueryIgnorer
+	MatchConfigurer
+	ArgsNormalizer
+	ClauseEnforcer
+	RawQueryMatcher
+	ColumnsChecker
+	WarningsCollector
+	OrderScoper
+	Rewriter
+	TransactionEnforcer
+	Simulator
+	LimitSimulator
+	LifetimeSimulator
+	SequenceSimulator
+	ReplicationSimulator
+	ThrottleSimulator
+	RowsTransformer
+	Validator
+	ColumnsValidator
+	DuplicatesMerger
+	Inspector
+	EventSubscriber
+	PrepareCounter
+	ArgsRecorder
+	LatencyReporter
+	Annotator
+	StatementCounter
+	Reporter
+	PoolInspector
+	FluentExpecter
+	CustomExpecter
+	StepExpecter
+	NamedExpecter
+	ArgRedactor
+}
+
+// As finds whether the mock, or rows, implement the extension
+// interface, which target points to, and if so, sets target to them.
+// It panics if target is not a non-nil pointer to an interface:
+//
+//	var sim sqlmock.Simulator
+//	if sqlmock.As(mock, &sim) {
+//		sim.Clock().Advance(time.Minute)
+//	}
+//
+//	var mapper sqlmock.RowsMapper
+//	if sqlmock.As(rows, &mapper) {
+//		mapper.AddRowMap(map[string]interface{}{"id": 1})
+//	}
+func As(mockOrRows interface{}, target interface{}) bool {
+	val := reflect.ValueOf(target)
+	if target == nil || val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Interface {
+		panic("sqlmock: target must be a non-nil pointer to an interface")
+	}
+
+	if mockOrRows == nil || !reflect.TypeOf(mockOrRows).Implements(val.Elem().Type()) {
+		return false
+	}
+	val.Elem().Set(reflect.ValueOf(mockOrRows))
+	return true
+}
+
+// CallInfo describes a database call handled by the mock
+type CallInfo struct {
+	Query string
+	Args  []driver.Value
+}
+
+// RowsMiddleware is applied to the rows returned for a call, after
+// the call has matched an expectation. It may modify given rows,
+// which are a copy of the expected ones, or return different rows.
+type RowsMiddleware func(call CallInfo, rows Rows) Rows
+
+// ErrDeadlock is returned for the statement chosen as a deadlock victim
+var ErrDeadlock = errors.New("ERROR: deadlock detected")
+
+// ErrTxInProgress is returned by Begin on a connection, which has a
+// transaction in progress, like when the driver connection is used
+// directly. Connections of the mock share its state, so that such a
+// Begin is rejected only while a single connection is open, with more
+// connections open it is taken to be made on another connection.
+var ErrTxInProgress = errors.New("there is already a transaction in progress")
+
+// ErrIdleInTransactionTimeout is returned for statements of a transaction,
+// which was terminated due to the simulated idle in transaction timeout.
+var ErrIdleInTransactionTimeout = errors.New("FATAL: terminating connection due to idle-in-transaction timeout")
+
+// MatchPolicy decides which expectation handles a call when
+// expectations are not matched in order and more than one
+// pending expectation matches it.
+type MatchPolicy int
+
+const (
+	// MatchFirst picks the earliest registered expectation
+	// which matches the call.
+	MatchFirst MatchPolicy = iota
+
+	// MatchBest picks the most specific expectation which matches
+	// the call. Expected arguments weigh the most, an argument
+	// compared by value being more specific than an Argument matcher,
+	// then the length of sql regexp. Ties are resolved in favor of
+	// the earliest registered expectation.
+	MatchBest
+
+	// MatchMostRecent picks the latest registered expectation
+	// which matches the call.
+	MatchMostRecent
+)
+
+// OrderScope selects kinds of expectations, which are matched
+// in order, see MatchInOrderOnly. Scopes may be combined.
+type OrderScope int
+
+const (
+	// OrderStatements orders Prepare(), Query() and Exec()
+	// expectations, including custom ones, and cancel requests
+	OrderStatements OrderScope = 1 << iota
+
+	// OrderTransactions orders Begin, Commit and Rollback
+	// expectations
+	OrderTransactions
+)
+
+// scopeOf returns the order scope of the expectation
+func scopeOf(e expectation) OrderScope {
+	switch e.(type) {
+	case *ExpectedPrepare, *ExpectedQuery, *ExpectedExec, *ExpectedCancel, *customExpectation:
+		return OrderStatements
+	case *ExpectedBegin, *ExpectedCommit, *ExpectedRollback:
+		return OrderTransactions
+	}
+	return 0
 }
 
 type sqlmock struct {
+	sync.Mutex
 	requireExpectations bool
-	ordered bool
-	dsn     string
-	opened  int
-	drv     *mockDriver
-
-	expected []expectation
+	ordered             bool
+	orderScope          OrderScope
+	policy              MatchPolicy
+	dsn                 string
+	handles             []string
+	opened              int
+	drv                 *mockDriver
+	clock               *Clock
+
+	idleTimeout  time.Duration
+	connLifetime time.Duration
+	connectedAt  time.Time
+	expiring     int
+	reconnects   int
+	maxParams    int
+	maxPacket    int
+	inTx         bool
+	txReadOnly   bool
+	txIdleSince  time.Time
+	txTerminated bool
+
+	allowedTables   map[string]bool
+	requiredClauses []*regexp.Regexp
+	normalizers     normalizers
+	rawQueries      bool
+	test            string
+	testFinished    bool
+	strictWarnings  bool
+	strictColumns   bool
+	validateColumns bool
+	rewriters       []QueryRewriter
+	dialect         Dialect
+	hasDialect      bool
+	lastWarnings    WarningsResult
+	slots           chan struct{}
+	openConns       int
+	poolRecording   bool
+	poolHistory     []PoolSample
+	step            string
+	stepIndex       int
+	steps           int
+	strictSteps     bool
+	slotTimeout     time.Duration
+	named           map[string]namedQuery
+	txRequired      bool
+	redact          *redactions
+
+	inFlight     int
+	peakInFlight int
+	subscribers  []chan Event
+	prepares     map[string]int
+	recording    bool
+	recorded     []Event
+	timing       bool
+	latencies    map[string][]time.Duration
+	latency      *LatencyProfile
+	sequences    map[string]*Sequence
+	lag          time.Duration
+	written      map[string]time.Time
+	warnings     []string
+	extractors   []AnnotationExtractor
+	annotated    []AnnotatedCall
+	statements   map[StatementKind]int
+	unexpected   int
+
+	ignored    []*IgnoredQueries
+	middleware []RowsMiddleware
+	expected   []expectation
 }
 
 func (s *sqlmock) open() (*sql.DB, Sqlmock, error) {
@@ -93,183 +890,723 @@ func (s *sqlmock) open() (*sql.DB, Sqlmock, error) {
 
 func (c *sqlmock) ExpectClose() *ExpectedClose {
 	e := &ExpectedClose{}
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
+// expect queues the expectation
+func (c *sqlmock) expect(e expectation) {
+	c.Lock()
+	if a, ok := e.(Expected); ok {
+		a.common().clock, a.common().redact = c.clock, c.redact
+		if c.step != "" {
+			a.common().step, a.common().stepIndex = c.step, c.stepIndex
+		}
+	}
+	c.expected = append(c.expected, e)
+	c.Unlock()
+}
+
 func (c *sqlmock) MatchExpectationsInOrder(b bool) {
-	c.ordered = b
+	c.Lock()
+	c.ordered, c.orderScope = b, 0
+	c.Unlock()
+}
+
+func (c *sqlmock) MatchInOrderOnly(scope OrderScope) {
+	c.Lock()
+	c.ordered, c.orderScope = scope != 0, scope
+	c.Unlock()
+}
+
+func (c *sqlmock) MatchExpectationsBy(policy MatchPolicy) {
+	c.Lock()
+	c.policy = policy
+	c.Unlock()
 }
 
 func (c *sqlmock) RequireExpectations(required bool) {
+	c.Lock()
 	c.requireExpectations = required
+	c.Unlock()
 }
 
-// Close a mock database driver connection. It may or may not
-// be called depending on the sircumstances, but if it is called
-// there must be an *ExpectedClose expectation satisfied.
-// meets http://golang.org/pkg/database/sql/driver/#Conn interface
-func (c *sqlmock) Close() (err error) {
-	c.drv.Lock()
-	defer c.drv.Unlock()
+func (c *sqlmock) UseRowsMiddleware(middleware ...RowsMiddleware) {
+	c.Lock()
+	c.middleware = append(c.middleware, middleware...)
+	c.Unlock()
+}
 
-	c.opened--
-	if c.opened == 0 {
-		delete(c.drv.conns, c.dsn)
+// cursor returns expected rows to be read by a call, rows built
+// by sqlmock are copied and passed through the middleware
+func (c *sqlmock) cursor(set driver.Rows, query string, args []driver.Value) driver.Rows {
+	if g, ok := set.(rowsGenerator); ok {
+		return c.applyMiddleware(CallInfo{Query: query, Args: args}, g.next())
+	}
+	if rs, ok := set.(*rows); ok {
+		return c.applyMiddleware(CallInfo{Query: query, Args: args}, rs.cursor())
+	}
+	return set
+}
+
+// applyMiddleware passes the rows through all the middleware
+func (c *sqlmock) applyMiddleware(call CallInfo, rs Rows) Rows {
+	c.Lock()
+	middleware := c.middleware
+	c.Unlock()
+
+	for _, mw := range middleware {
+		rs = mw(call, rs)
 	}
+	return rs
+}
 
-	var expected *ExpectedClose
+func (c *sqlmock) Clock() *Clock {
+	return c.clock
+}
+
+func (c *sqlmock) IdleInTransactionTimeout(timeout time.Duration) {
+	c.Lock()
+	c.idleTimeout = timeout
+	c.Unlock()
+}
+
+// beginTx marks a transaction to be in progress
+func (c *sqlmock) beginTx(readOnly bool) {
+	c.Lock()
+	c.inTx, c.txTerminated, c.txReadOnly = true, false, readOnly
+	c.txIdleSince = c.clock.Now()
+	c.samplePool()
+	c.Unlock()
+}
+
+// enter records a call to be in flight, once it acquires one of the
+// slots, if calls in flight are limited, see LimitInFlight
+func (c *sqlmock) enter() (slots chan struct{}, err error) {
+	c.Lock()
+	slots, timeout := c.slots, c.slotTimeout
+	c.Unlock()
+
+	if err = acquire(slots, timeout, c.clock); err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	c.inFlight++
+	if c.inFlight > c.peakInFlight {
+		c.peakInFlight = c.inFlight
+	}
+	c.samplePool()
+	c.Unlock()
+	return slots, nil
+}
+
+// leave records a call in flight to be finished and releases
+// the slot it acquired
+func (c *sqlmock) leave(slots chan struct{}) {
+	c.Lock()
+	c.inFlight--
+	c.samplePool()
+	c.Unlock()
+	if slots != nil {
+		<-slots
+	}
+}
+
+func (c *sqlmock) PeakInFlight() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.peakInFlight
+}
+
+func (c *sqlmock) PrepareCounts() map[string]int {
+	c.Lock()
+	defer c.Unlock()
+
+	counts := make(map[string]int, len(c.prepares))
+	for query, n := range c.prepares {
+		counts[query] = n
+	}
+	return counts
+}
+
+func (c *sqlmock) PreparedAtMost(n int) error {
+	var excess []string
+	for query, count := range c.PrepareCounts() {
+		if count > n {
+			excess = append(excess, fmt.Sprintf("'%s' (%d)", query, count))
+		}
+	}
+
+	if len(excess) > 0 {
+		sort.Strings(excess)
+		return fmt.Errorf("queries were prepared more than %d times: %s", n, strings.Join(excess, ", "))
+	}
+	return nil
+}
+
+func (c *sqlmock) WithoutQueryNormalization() {
+	c.Lock()
+	c.rawQueries = true
+	c.Unlock()
+}
+
+// strip rewrites the query and its args by the query rewriters, if
+// any, and collapses whitespace of the query, unless query
+// normalization is disabled
+func (c *sqlmock) strip(query string, args []driver.Value) (string, []driver.Value) {
+	c.Lock()
+	raw, rewriters := c.rawQueries, c.rewriters
+	c.Unlock()
+
+	for _, rewrite := range rewriters {
+		query, args = rewrite(query, args)
+	}
+	if raw {
+		return query, args
+	}
+	return stripQuery(query), args
+}
+
+// touchTx records a statement executed within the transaction in progress,
+// unless the transaction was idle for longer than the idle in transaction
+// timeout, in which case it is terminated. The transaction is finished
+// if end is true.
+func (c *sqlmock) touchTx(end bool) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.inTx {
+		return nil
+	}
+
+	now := c.clock.Now()
+	if c.idleTimeout > 0 && now.Sub(c.txIdleSince) > c.idleTimeout {
+		c.txTerminated = true
+	}
+
+	terminated := c.txTerminated
+	c.txIdleSince = now
+	if end {
+		c.inTx, c.txTerminated, c.txReadOnly = false, false, false
+		c.samplePool()
+	}
+
+	if terminated {
+		return ErrIdleInTransactionTimeout
+	}
+	return nil
+}
+
+// inOrder tells whether the expectation is matched in order,
+// must be called under the mock lock
+func (c *sqlmock) inOrder(e expectation) bool {
+	return c.ordered && (c.orderScope == 0 || c.orderScope&scopeOf(e) != 0)
+}
+
+// matchExpectation looks up a pending expectation which should handle
+// the call. In ordered mode only the next pending expectation is taken,
+// if it is not of the expected kind it is returned as next, so that the
+// caller could report it. Otherwise the match policy chooses among all
+// pending expectations of the expected kind which accept the call and
+// have the highest priority. Reusable expectations do not take part in
+// ordering and are matched only if there is no other expectation to
+// handle the call. The matched expectation is returned locked. When
+// nothing matched, exhausted tells whether all the expectations were
+// already fulfilled.
+func (c *sqlmock) matchExpectation(kind, accepts func(expectation) bool) (matched, next expectation, exhausted bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	// candidates are collected on stack for the usual few of them
+	var candidatesBuf, reusableBuf [4]expectation
+	candidates, reusable := candidatesBuf[:0], reusableBuf[:0]
+	var pending expectation
+	var pendingKind, pendingFits bool
+	var pendingGroup *alternatives
+	var blocker expectation
 	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
+	callOrdered := c.orderScope == 0
+	for _, e := range c.expected {
+		if !callOrdered && kind(e) {
+			// the call is ordered, if expectations of its kind are
+			callOrdered = c.inOrder(e)
+		}
+
+		e.Lock()
+		if e.fulfilled() {
+			e.Unlock()
 			fulfilled++
 			continue
 		}
 
-		if expected, ok = next.(*ExpectedClose); ok {
-			break
+		if c.strictSteps && !e.reusable() && stepOf(e) > 0 && (blocker == nil || stepOf(e) < stepOf(blocker)) {
+			blocker = e // of the earliest step, which has not completed
 		}
 
-		next.Unlock()
-		if c.ordered {
-			return fmt.Errorf("call to database Close, was not expected, next expectation is: %s", next)
+		fits := kind(e) && (accepts == nil || accepts(e))
+		switch {
+		case e.reusable():
+			fulfilled++
+			if fits {
+				reusable = append(reusable, e)
+			}
+		case c.inOrder(e):
+			if pending == nil {
+				pending, pendingKind, pendingFits = e, kind(e), fits
+				pendingGroup = groupOf(e)
+			} else if fits && !pendingFits && pendingGroup != nil && groupOf(e) == pendingGroup {
+				// an alternative of the next expectation is matched instead
+				pending, pendingKind, pendingFits = e, true, true
+			}
+		case fits:
+			candidates = append(candidates, e)
 		}
+		e.Unlock()
+	}
+
+	// the lock on mock prevents candidates to be matched by other calls
+	if callOrdered && pending != nil {
+		switch {
+		case pendingFits:
+			if blocker != nil && stepOf(pending) > stepOf(blocker) {
+				c.unexpected++
+				return nil, blocker, false
+			}
+			pending.Lock()
+			return pending, nil, false
+		case len(reusable) > 0:
+			c.warnings = append(c.warnings, fmt.Sprintf("call was matched by reusable expectation as a fallback, while next expectation is: %s", lockedString(pending)))
+		case pendingKind:
+			// let the caller report why it does not match
+			c.unexpected++
+			pending.Lock()
+			return pending, nil, false
+		default:
+			c.unexpected++
+			return nil, pending, false
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates = reusable
 	}
 
+	if len(candidates) == 0 {
+		c.unexpected++
+		return nil, nil, fulfilled == len(c.expected)
+	}
+
+	top := candidates[:0]
+	for _, e := range candidates {
+		if len(top) > 0 && priority(e) < priority(top[0]) {
+			continue
+		}
+		if len(top) > 0 && priority(e) > priority(top[0]) {
+			top = top[:0]
+		}
+		top = append(top, e)
+	}
+	candidates = top
+
+	matched = candidates[0]
+	switch c.policy {
+	case MatchMostRecent:
+		matched = candidates[len(candidates)-1]
+	case MatchBest:
+		best := specificity(matched)
+		for _, e := range candidates[1:] {
+			if score := specificity(e); score > best {
+				matched, best = e, score
+			}
+		}
+	}
+	if blocker != nil && stepOf(matched) > stepOf(blocker) {
+		c.unexpected++
+		return nil, blocker, false
+	}
+	matched.Lock()
+	return matched, nil, false
+}
+
+// hint tells which pending expectation of the given kind was probably
+// meant by a call, which did not match any, to append to the error or
+// warning about the call. It is empty, if there is none.
+func (c *sqlmock) hint(kind func(expectation) bool, query string, args []driver.Value) string {
+	if closest := c.closestExpectation(kind, query, args); closest != nil {
+		return ", did you mean: " + lockedString(closest)
+	}
+	return ""
+}
+
+// closestExpectation looks up a pending expectation of the given kind,
+// which is the most similar to the query and its arguments. It is used
+// to hint what was probably meant, when a call does not match any.
+func (c *sqlmock) closestExpectation(kind func(expectation) bool, query string, args []driver.Value) (closest expectation) {
+	c.Lock()
+	defer c.Unlock()
+
+	best := -1
+	for _, e := range c.expected {
+		e.Lock()
+		if !e.fulfilled() && kind(e) {
+			if d, ok := e.(interface {
+				distance(string, []driver.Value) int
+			}); ok {
+				if dist := d.distance(query, args); best < 0 || dist < best {
+					closest, best = e, dist
+				}
+			}
+		}
+		e.Unlock()
+	}
+	return
+}
+
+// failf formats an error of the mock, which is tagged with the
+// handle the call was made on, if any
+func failf(handle, format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	if handle != "" {
+		err = fmt.Errorf("%s: %s", handle, err)
+	}
+	return err
+}
+
+// lockedString formats the expectation under its lock,
+// since it may be concurrently matched by other calls
+func lockedString(e expectation) string {
+	e.Lock()
+	defer e.Unlock()
+	return e.String()
+}
+
+// priority returns the priority of expectation
+func priority(e expectation) int {
+	if p, ok := e.(interface {
+		getPriority() int
+	}); ok {
+		return p.getPriority()
+	}
+	return 0
+}
+
+// specificity scores how specific the expectation is
+// in order to choose the best match among candidates
+func specificity(e expectation) int {
+	if s, ok := e.(interface {
+		specificity() int
+	}); ok {
+		return s.specificity()
+	}
+	return 0
+}
+
+// Close a mock database driver connection. It may or may not
+// be called depending on the sircumstances, but if it is called
+// there must be an *ExpectedClose expectation satisfied.
+// meets http://golang.org/pkg/database/sql/driver/#Conn interface
+func (c *sqlmock) Close() error {
+	return c.close("")
+}
+
+func (c *sqlmock) close(handle string) (err error) {
+	c.drv.Lock()
+	defer c.drv.Unlock()
+
+	c.opened--
+	c.connsChanged(c.opened)
+	if expired := c.closeExpired(); c.opened == 0 && !expired {
+		c.drv.remove(c)
+		c.Lock()
+		c.unsubscribeAll()
+		c.Unlock()
+	}
+
+	matched, next, exhausted := c.matchExpectation(func(e expectation) bool {
+		_, ok := e.(*ExpectedClose)
+		return ok
+	}, nil)
+	if next != nil {
+		return failf(handle, "call to database Close, was not expected, next expectation is: %s", lockedString(next))
+	}
+
+	expected, _ := matched.(*ExpectedClose)
 	if expected == nil {
 		if c.requireExpectations {
 			msg := "call to database Close was not expected"
-			if fulfilled == len(c.expected) {
+			if exhausted {
 				msg = "all expectations were already fulfilled, " + msg
 			}
-			return fmt.Errorf(msg)
+			return failf(handle, msg)
 		}
 	} else {
 		err = expected.err
-		expected.triggered = true
+		expected.trigger()
 		expected.Unlock()
 	}
 
 	return err
 }
 
+// ExpectationsWereMet is safe to be called concurrently with database
+// calls and any number of times. It checks the snapshot of expectations
+// taken when it is called.
 func (c *sqlmock) ExpectationsWereMet() error {
-	for _, e := range c.expected {
-		if !e.fulfilled() {
-			return fmt.Errorf("there is a remaining expectation which was not matched: %s", e)
+	c.Lock()
+	expected := make([]expectation, len(c.expected))
+	copy(expected, c.expected)
+	c.Unlock()
+
+	for _, e := range expected {
+		e.Lock()
+		fulfilled := e.fulfilled() || e.reusable()
+		e.Unlock()
+
+		if !fulfilled {
+			return fmt.Errorf("there is a remaining expectation which was not matched: %s", lockedString(e))
+		}
+		if v, ok := e.(verifiable); ok {
+			if err := v.verify(); err != nil {
+				return err
+			}
+		}
+		if a, ok := e.(Expected); ok {
+			if err := a.common().checkWindow(e); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+	return c.warningsError()
 }
 
 // Begin meets http://golang.org/pkg/database/sql/driver/#Conn interface
-func (c *sqlmock) Begin() (res driver.Tx, err error) {
-	var expected *ExpectedBegin
-	var ok bool
-	var fulfilled int
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+func (c *sqlmock) Begin() (driver.Tx, error) {
+	return c.begin("", driver.TxOptions{})
+}
 
-		if expected, ok = next.(*ExpectedBegin); ok {
-			break
-		}
+func (c *sqlmock) begin(handle string, opts driver.TxOptions) (res driver.Tx, err error) {
+	if err = c.guard(handle); err != nil {
+		return nil, err
+	}
+	if err = c.expire(); err != nil {
+		return nil, err
+	}
 
-		next.Unlock()
-		if c.ordered {
-			return nil, fmt.Errorf("call to database transaction Begin, was not expected, next expectation is: %s", next)
-		}
+	defer func() { c.emitResult(TxBegan, TxFailed, handle, "", nil, err) }()
+
+	if c.nestedTx() {
+		return nil, ErrTxInProgress
+	}
+
+	readOnly := opts.ReadOnly
+	matched, next, exhausted := c.matchExpectation(func(e expectation) bool {
+		_, ok := e.(*ExpectedBegin)
+		return ok
+	}, nil)
+	if next != nil {
+		return nil, failf(handle, "call to database transaction Begin, was not expected, next expectation is: %s", lockedString(next))
 	}
 
+	expected, _ := matched.(*ExpectedBegin)
 	if expected == nil {
 		if c.requireExpectations {
 			msg := "call to database transaction Begin was not expected"
-			if fulfilled == len(c.expected) {
+			if exhausted {
 				msg = "all expectations were already fulfilled, " + msg
 			}
-			return nil, fmt.Errorf(msg)
+			return nil, failf(handle, msg)
 		}
+		c.warnf("call to database transaction Begin was not expected, tolerated since expectations are not required")
 	} else {
 		err = expected.err
-		expected.triggered = true
+		readOnly = readOnly || expected.readOnly
+		expected.trigger()
 		expected.Unlock()
 	}
 
-	return c, err
+	if err != nil {
+		return nil, err
+	}
+
+	c.beginTx(readOnly)
+	return &transaction{conn: c, handle: handle}, nil
 }
 
 func (c *sqlmock) ExpectBegin() *ExpectedBegin {
 	e := &ExpectedBegin{}
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
+// nestedTx tells whether a transaction is begun on the connection,
+// which has a transaction in progress. Since connections share the
+// mock, it is known only when a single connection is open, as
+// database/sql begins a transaction on a connection without one.
+func (c *sqlmock) nestedTx() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.inTx && c.openConns == 1
+}
+
+// checkReadOnly rejects a stripped query, which modifies data
+// within a read-only transaction
+func (c *sqlmock) checkReadOnly(query string) error {
+	c.Lock()
+	readOnly := c.txReadOnly
+	c.Unlock()
+
+	if !readOnly {
+		return nil
+	}
+	if verb := modifyingStatement(query); verb != "" {
+		return fmt.Errorf("ERROR: cannot execute %s in a read-only transaction", verb)
+	}
+	return nil
+}
+
 // Exec meets http://golang.org/pkg/database/sql/driver/#Execer
-func (c *sqlmock) Exec(query string, args []driver.Value) (res driver.Result, err error) {
-	query = stripQuery(query)
-	var expected *ExpectedExec
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+func (c *sqlmock) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return c.exec(context.Background(), "", query, args)
+}
 
-		if c.ordered {
-			if expected, ok = next.(*ExpectedExec); ok {
-				break
-			}
-			next.Unlock()
-			return nil, fmt.Errorf("call to exec query '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
-		}
-		if exec, ok := next.(*ExpectedExec); ok {
-			if exec.attemptMatch(query, args) {
-				expected = exec
-				break
+func (c *sqlmock) exec(ctx context.Context, handle, query string, args []driver.Value) (res driver.Result, err error) {
+	slots, err := c.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer c.leave(slots)
+
+	if err = c.guard(handle); err != nil {
+		return nil, err
+	}
+	if err = c.expire(); err != nil {
+		return nil, err
+	}
+
+	query, args = c.strip(query, args)
+	defer c.observe(query, time.Now())
+	c.emit(Event{Kind: ExecStarted, Handle: handle, Query: query, Args: args})
+	defer func() { c.emitResult(ExecMatched, ExecFailed, handle, query, args, err) }()
+	var consumed bool
+	defer func() {
+		if err == nil {
+			c.replicate(handle, query)
+			if consumed {
+				c.count(query)
 			}
 		}
-		next.Unlock()
+		c.recordWarnings(res)
+	}()
+
+	if err = c.touchTx(false); err != nil {
+		return nil, err
+	}
+
+	if ignored := c.ignoredQuery(query); ignored != nil {
+		return ignored.result, nil
+	}
+
+	if err = c.checkTables(query); err != nil {
+		return nil, failf(handle, "%s", err)
 	}
 
+	if err = c.checkClauses(query); err != nil {
+		return nil, failf(handle, "%s", err)
+	}
+
+	if err = c.checkReadOnly(query); err != nil {
+		return nil, err
+	}
+
+	if err = c.checkTransaction(query); err != nil {
+		return nil, failf(handle, "%s", err)
+	}
+
+	if err = c.checkLimits(query, args); err != nil {
+		return nil, err
+	}
+
+	if kind := c.customKind(query); kind != "" {
+		res, _, err = c.handleCustom(handle, kind, query, args)
+		return res, err
+	}
+
+	latency, norm := c.latencyProfile(), c.argNormalizers()
+	kind := func(e expectation) bool {
+		_, ok := e.(*ExpectedExec)
+		return ok
+	}
+	matched, next, exhausted := c.matchExpectation(kind, func(e expectation) bool {
+		return e.(*ExpectedExec).attemptMatch(query, args, norm) && e.(*ExpectedExec).handleMatches(handle)
+	})
+	if next != nil {
+		return nil, failf(handle, "call to exec query '%s' with args %+v, was not expected, next expectation is: %s", query, c.redact.args(args), lockedString(next))
+	}
+
+	expected, _ := matched.(*ExpectedExec)
 	if expected == nil {
+		hint := c.hint(kind, query, args)
 		if c.requireExpectations {
 			msg := "call to exec '%s' query with args %+v was not expected"
-			if fulfilled == len(c.expected) {
+			if exhausted {
 				msg = "all expectations were already fulfilled, " + msg
 			}
-			return nil, fmt.Errorf(msg, query, args)
+			return nil, failf(handle, msg+"%s", query, c.redact.args(args), hint)
 		}
+		c.warnf("call to exec '%s' query with args %+v was not expected, tolerated since expectations are not required%s", query, c.redact.args(args), hint)
 	} else {
 		defer expected.Unlock()
-		expected.triggered = true
-		// converts panic to error in case of reflect value type mismatch
-		defer func(errp *error, exp *ExpectedExec, q string, a []driver.Value) {
-			if e := recover(); e != nil {
-				if se, ok := e.(*reflect.ValueError); ok { // catch reflect error, failed type conversion
-					msg := "exec query \"%s\", args \"%+v\" failed to match with error \"%s\" expectation: %s"
-					*errp = fmt.Errorf(msg, q, a, se, exp)
-				} else {
-					panic(e) // overwise if unknown error panic
-				}
+		consumed = true
+		expected.trigger()
+		if !expected.queryMatches(query) {
+			return nil, failf(handle, "exec query '%s', does not match regex '%s'", query, expected.sqlRegex.String())
+		}
+
+		if mismatch := expected.argsMismatch(args, norm); mismatch != nil {
+			return nil, failf(handle, "exec query '%s', args %+v does not match expected %+v: %s", query, c.redact.args(args), c.redact.args(expected.args), c.redact.mismatch(mismatch, expected.args, args))
+		}
+
+		if !expected.handleMatches(handle) {
+			return nil, failf(handle, "exec query '%s' was expected to be made on handle %s", query, expected.onHandle)
+		}
+
+		if err = expected.checkContext(ctx); err != nil {
+			return nil, failf(handle, "exec query '%s' was made with an unexpected context: %s", query, err)
+		}
+
+		if expected.timesOut {
+			// the server cancels the statement, once it runs for the timeout
+			timeout := expected.timeout
+			expected.Unlock()
+			if err = sleep(ctx, timeout); err != nil {
+				err = c.cancel(handle, query, err)
+			} else {
+				err = c.timeoutError()
 			}
-		}(&err, expected, query, args)
+			expected.Lock()
+			return nil, err
+		}
 
-		if !expected.queryMatches(query) {
-			return nil, fmt.Errorf("exec query '%s', does not match regex '%s'", query, expected.sqlRegex.String())
+		if delay := expected.delayFor(latency); delay > 0 {
+			expected.Unlock()
+			if err = sleep(ctx, delay); err != nil {
+				err = c.cancel(handle, query, err)
+			}
+			expected.Lock()
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		if !expected.argsMatches(args) {
-			return nil, fmt.Errorf("exec query '%s', args %+v does not match expected %+v", query, args, expected.args)
+		if expected.deadlock != nil {
+			// wait for the conflicting statement without holding the expectation
+			expected.Unlock()
+			err = expected.deadlock.wait(expected.sqlRegex)
+			expected.Lock()
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		if expected.err != nil {
@@ -277,10 +1614,13 @@ func (c *sqlmock) Exec(query string, args []driver.Value) (res driver.Result, er
 		}
 
 		if expected.result == nil {
-			return nil, fmt.Errorf("exec query '%s' with args %+v, must return a database/sql/driver.result, but it was not set for expectation %T as %+v", query, args, expected, expected)
+			return nil, failf(handle, "exec query '%s' with args %+v, must return a database/sql/driver.result, but it was not set for expectation %T as %+v", query, c.redact.args(args), expected, expected)
 		}
 
 		res = expected.result
+		if g, ok := res.(resultGenerator); ok {
+			res = g.next()
+		}
 	}
 
 	return res, err
@@ -289,116 +1629,269 @@ func (c *sqlmock) Exec(query string, args []driver.Value) (res driver.Result, er
 func (c *sqlmock) ExpectExec(sqlRegexStr string) *ExpectedExec {
 	e := &ExpectedExec{}
 	e.sqlRegex = regexp.MustCompile(sqlRegexStr)
-	c.expected = append(c.expected, e)
+	c.expect(e)
+	return e
+}
+
+func (c *sqlmock) ExpectExecFailingTx(sqlRegexStr string, err error) *ExpectedExec {
+	e := c.ExpectExec(sqlRegexStr).WillReturnError(err)
+	c.ExpectRollback()
 	return e
 }
 
+func (c *sqlmock) ExpectDeadlock(firstSQLRegexStr, secondSQLRegexStr string) (*ExpectedExec, *ExpectedExec) {
+	first, second := c.ExpectExec(firstSQLRegexStr), c.ExpectExec(secondSQLRegexStr)
+	dl := &deadlock{release: make(chan struct{}), timeout: deadlockTimeout, first: first.sqlRegex, second: second.sqlRegex}
+	first.deadlock, second.deadlock = dl, dl
+	return first, second
+}
+
 // Prepare meets http://golang.org/pkg/database/sql/driver/#Conn interface
-func (c *sqlmock) Prepare(query string) (res driver.Stmt, err error) {
-	var expected *ExpectedPrepare
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+func (c *sqlmock) Prepare(query string) (driver.Stmt, error) {
+	return c.prepare("", query)
+}
 
-		if expected, ok = next.(*ExpectedPrepare); ok {
-			break
-		}
+func (c *sqlmock) prepare(handle, query string) (res driver.Stmt, err error) {
+	if err = c.guard(handle); err != nil {
+		return nil, err
+	}
+	if err = c.expire(); err != nil {
+		return nil, err
+	}
 
-		next.Unlock()
-		if c.ordered {
-			return nil, fmt.Errorf("call to Prepare stetement with query '%s', was not expected, next expectation is: %s", query, next)
-		}
+	stripped, _ := c.strip(query, nil)
+	c.Lock()
+	if c.prepares == nil {
+		c.prepares = make(map[string]int)
 	}
+	c.prepares[stripped]++
+	c.Unlock()
 
-	query = stripQuery(query)
+	if err = c.touchTx(false); err != nil {
+		return nil, err
+	}
+
+	if c.ignoredQuery(stripped) != nil {
+		return &statement{conn: c, handle: handle, query: stripped}, nil
+	}
+
+	if err = c.checkTables(stripped); err != nil {
+		return nil, failf(handle, "%s", err)
+	}
+
+	if err = c.checkClauses(stripped); err != nil {
+		return nil, failf(handle, "%s", err)
+	}
+
+	if key := c.preparedKey(stripped); key != "" {
+		return nil, failf(handle, "statement '%s' with query '%s' was prepared again, but it should be prepared once and cached", key, stripped)
+	}
+
+	kind := func(e expectation) bool {
+		_, ok := e.(*ExpectedPrepare)
+		return ok
+	}
+	matched, next, exhausted := c.matchExpectation(kind, func(e expectation) bool {
+		return e.(*ExpectedPrepare).sqlRegex.MatchString(stripped)
+	})
+	if next != nil {
+		return nil, failf(handle, "call to Prepare stetement with query '%s', was not expected, next expectation is: %s", query, lockedString(next))
+	}
+
+	query = stripped
+	expected, _ := matched.(*ExpectedPrepare)
 	if expected == nil {
+		hint := c.hint(kind, query, nil)
 		if c.requireExpectations {
 			msg := "call to Prepare '%s' query was not expected"
-			if fulfilled == len(c.expected) {
+			if exhausted {
 				msg = "all expectations were already fulfilled, " + msg
 			}
-			return nil, fmt.Errorf(msg, query)
+			return nil, failf(handle, msg+"%s", query, hint)
 		}
+		c.warnf("call to Prepare '%s' query was not expected, tolerated since expectations are not required%s", query, hint)
+		res = &statement{conn: c, handle: handle, query: query} // database/sql requires a statement
 	} else {
-		expected.triggered = true
+		expected.trigger()
+		if !expected.sqlRegex.MatchString(query) {
+			expected.Unlock()
+			return nil, failf(handle, "Prepare query '%s', does not match regex [%s]", query, expected.sqlRegex.String())
+		}
 		expected.Unlock()
-		res, err = &statement{c, query, expected.closeErr}, expected.err
+		res, err = &statement{conn: c, handle: handle, query: query, err: expected.closeErr, prepared: expected}, expected.err
 	}
 
 	return res, err
 }
 
+// preparedKey returns the key of an already prepared statement,
+// which matches the stripped query, if any
+func (c *sqlmock) preparedKey(query string) string {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, e := range c.expected {
+		if p, ok := e.(*ExpectedPrepare); ok {
+			p.Lock()
+			prepared := p.key != "" && p.triggered && p.sqlRegex.MatchString(query)
+			p.Unlock()
+			if prepared {
+				return p.key
+			}
+		}
+	}
+	return ""
+}
+
 func (c *sqlmock) ExpectPrepare(sqlRegexStr string) *ExpectedPrepare {
 	e := &ExpectedPrepare{sqlRegex: regexp.MustCompile(sqlRegexStr), mock: c}
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
 // Query meets http://golang.org/pkg/database/sql/driver/#Queryer
-func (c *sqlmock) Query(query string, args []driver.Value) (rw driver.Rows, err error) {
-	query = stripQuery(query)
-	var expected *ExpectedQuery
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
+func (c *sqlmock) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return c.query(context.Background(), "", query, args)
+}
+
+func (c *sqlmock) query(ctx context.Context, handle, query string, args []driver.Value) (rw driver.Rows, err error) {
+	slots, err := c.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer c.leave(slots)
+
+	if err = c.guard(handle); err != nil {
+		return nil, err
+	}
+	if err = c.expire(); err != nil {
+		return nil, err
+	}
+
+	query, args = c.strip(query, args)
+	defer c.observe(query, time.Now())
+	c.emit(Event{Kind: QueryStarted, Handle: handle, Query: query, Args: args})
+	defer func() { c.emitResult(QueryMatched, QueryFailed, handle, query, args, err) }()
+	var consumed bool
+	defer func() {
+		if err == nil && !hasPrefixFold(query, "SELECT ") {
+			c.replicate(handle, query) // like INSERT ... RETURNING
+		}
+		if err == nil && consumed {
+			c.count(query)
 		}
+	}()
 
-		if c.ordered {
-			if expected, ok = next.(*ExpectedQuery); ok {
-				break
-			}
-			next.Unlock()
-			return nil, fmt.Errorf("call to query '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
+	if err = c.touchTx(false); err != nil {
+		return nil, err
+	}
+
+	if ignored := c.ignoredQuery(query); ignored != nil {
+		if rs, ok := ignored.rows.(*rows); ok {
+			return rs.cursor(), nil
 		}
-		if qr, ok := next.(*ExpectedQuery); ok {
-			if qr.attemptMatch(query, args) {
-				expected = qr
-				break
-			}
+		return ignored.rows, nil
+	}
+
+	if err = c.checkTables(query); err != nil {
+		return nil, failf(handle, "%s", err)
+	}
+
+	if err = c.checkClauses(query); err != nil {
+		return nil, failf(handle, "%s", err)
+	}
+
+	if err = c.checkReadOnly(query); err != nil {
+		return nil, err
+	}
+
+	if err = c.checkTransaction(query); err != nil {
+		return nil, failf(handle, "%s", err)
+	}
+
+	if err = c.checkLimits(query, args); err != nil {
+		return nil, err
+	}
+
+	if kind := c.customKind(query); kind != "" {
+		if _, rw, err = c.handleCustom(handle, kind, query, args); err == nil && rw == nil && c.requireExpectations {
+			return nil, failf(handle, "%s '%s' with args %+v, must return rows, but the expectation returned none", kind, query, c.redact.args(args))
 		}
-		next.Unlock()
+		return rw, err
+	}
+
+	latency, norm := c.latencyProfile(), c.argNormalizers()
+	strict, validate := c.columnChecks()
+	stale := c.lagging(handle, query)
+	kind := func(e expectation) bool {
+		_, ok := e.(*ExpectedQuery)
+		return ok
+	}
+	matched, next, exhausted := c.matchExpectation(kind, func(e expectation) bool {
+		return e.(*ExpectedQuery).attemptMatch(query, args, norm) && e.(*ExpectedQuery).handleMatches(handle)
+	})
+	if next != nil {
+		return nil, failf(handle, "call to query '%s' with args %+v, was not expected, next expectation is: %s", query, c.redact.args(args), lockedString(next))
 	}
 
+	expected, _ := matched.(*ExpectedQuery)
 	if expected == nil {
+		if rs := c.warningRows(query); rs != nil {
+			return c.cursor(rs, query, args), nil
+		}
+		hint := c.hint(kind, query, args)
 		if c.requireExpectations {
 			msg := "call to query '%s' with args %+v was not expected"
-			if fulfilled == len(c.expected) {
+			if exhausted {
 				msg = "all expectations were already fulfilled, " + msg
 			}
-			return nil, fmt.Errorf(msg, query, args)
+			return nil, failf(handle, msg+"%s", query, c.redact.args(args), hint)
 		}
+		c.warnf("call to query '%s' with args %+v was not expected, tolerated since expectations are not required%s", query, c.redact.args(args), hint)
+		rw = NewRows(nil).(*rows).cursor() // database/sql requires rows
 	} else {
 		defer expected.Unlock()
-		expected.triggered = true
-		// converts panic to error in case of reflect value type mismatch
-		defer func(errp *error, exp *ExpectedQuery, q string, a []driver.Value) {
-			if e := recover(); e != nil {
-				if se, ok := e.(*reflect.ValueError); ok { // catch reflect error, failed type conversion
-					msg := "query \"%s\", args \"%+v\" failed to match with error \"%s\" expectation: %s"
-					*errp = fmt.Errorf(msg, q, a, se, exp)
-				} else {
-					panic(e) // overwise if unknown error panic
-				}
-			}
-		}(&err, expected, query, args)
-
+		consumed = true
+		call := expected.calls
+		expected.trigger()
 		if !expected.queryMatches(query) {
-			return nil, fmt.Errorf("query '%s', does not match regex [%s]", query, expected.sqlRegex.String())
+			return nil, failf(handle, "query '%s', does not match regex [%s]", query, expected.sqlRegex.String())
+		}
+
+		if mismatch := expected.argsMismatch(args, norm); mismatch != nil {
+			return nil, failf(handle, "query '%s', args %+v does not match expected %+v: %s", query, c.redact.args(args), c.redact.args(expected.args), c.redact.mismatch(mismatch, expected.args, args))
+		}
+
+		if !expected.handleMatches(handle) {
+			return nil, failf(handle, "query '%s' was expected to be made on handle %s", query, expected.onHandle)
+		}
+
+		if err = expected.checkContext(ctx); err != nil {
+			return nil, failf(handle, "query '%s' was made with an unexpected context: %s", query, err)
+		}
+
+		if expected.timesOut {
+			// the server cancels the statement, once it runs for the timeout
+			timeout := expected.timeout
+			expected.Unlock()
+			if err = sleep(ctx, timeout); err != nil {
+				err = c.cancel(handle, query, err)
+			} else {
+				err = c.timeoutError()
+			}
+			expected.Lock()
+			return nil, err
 		}
 
-		if !expected.argsMatches(args) {
-			return nil, fmt.Errorf("query '%s', args %+v does not match expected %+v", query, args, expected.args)
+		if delay := expected.delayFor(latency); delay > 0 {
+			expected.Unlock()
+			if err = sleep(ctx, delay); err != nil {
+				err = c.cancel(handle, query, err)
+			}
+			expected.Lock()
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		if expected.err != nil {
@@ -406,10 +1899,41 @@ func (c *sqlmock) Query(query string, args []driver.Value) (rw driver.Rows, err
 		}
 
 		if expected.rows == nil {
-			return nil, fmt.Errorf("query '%s' with args %+v, must return a database/sql/driver.rows, but it was not set for expectation %T as %+v", query, args, expected, expected)
+			return nil, failf(handle, "query '%s' with args %+v, must return a database/sql/driver.rows, but it was not set for expectation %T as %+v", query, c.redact.args(args), expected, expected)
 		}
 
-		rw = expected.rows
+		sets := expected.rowsFor(call)
+		if stale {
+			sets = expected.staleRows(sets)
+		}
+		for _, set := range sets {
+			if rs, ok := set.(RowsBuildReporter); ok && rs.Err() != nil {
+				return nil, failf(handle, "query '%s' with args %+v, rows of the expectation failed to build: %s", query, c.redact.args(args), rs.Err())
+			}
+			if !validate {
+				continue
+			}
+			if problem := columnProblem(set); problem != "" {
+				return nil, failf(handle, "query '%s' with args %+v, rows of the expectation are not valid: %s", query, c.redact.args(args), problem)
+			}
+		}
+		if strict {
+			if err := checkColumns(query, sets[0].Columns()); err != nil {
+				return nil, failf(handle, "%s", err)
+			}
+		}
+		if len(sets) == 1 {
+			rw = c.cursor(sets[0], query, args)
+		} else {
+			cursors := make([]driver.Rows, len(sets))
+			for i, set := range sets {
+				cursors[i] = c.cursor(set, query, args)
+			}
+			rw = &resultSets{sets: cursors}
+		}
+		if expected.queryRow {
+			rw = &queryRowRows{Rows: rw, expected: expected}
+		}
 	}
 
 	return rw, err
@@ -418,55 +1942,63 @@ func (c *sqlmock) Query(query string, args []driver.Value) (rw driver.Rows, err
 func (c *sqlmock) ExpectQuery(sqlRegexStr string) *ExpectedQuery {
 	e := &ExpectedQuery{}
 	e.sqlRegex = regexp.MustCompile(sqlRegexStr)
-	c.expected = append(c.expected, e)
+	c.expect(e)
+	return e
+}
+
+func (c *sqlmock) ExpectQueryRow(sqlRegexStr string) *ExpectedQuery {
+	e := c.ExpectQuery(sqlRegexStr)
+	e.queryRow = true
 	return e
 }
 
 func (c *sqlmock) ExpectCommit() *ExpectedCommit {
 	e := &ExpectedCommit{}
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
 func (c *sqlmock) ExpectRollback() *ExpectedRollback {
 	e := &ExpectedRollback{}
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
 // Commit meets http://golang.org/pkg/database/sql/driver/#Tx
-func (c *sqlmock) Commit() (err error) {
-	var expected *ExpectedCommit
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+func (c *sqlmock) Commit() error {
+	return c.commit("")
+}
 
-		if expected, ok = next.(*ExpectedCommit); ok {
-			break
-		}
+func (c *sqlmock) commit(handle string) (err error) {
+	defer func() { c.emitResult(TxCommitted, TxFailed, handle, "", nil, err) }()
 
-		next.Unlock()
-		if c.ordered {
-			return fmt.Errorf("call to commit transaction, was not expected, next expectation is: %s", next)
-		}
+	if err = c.guard(handle); err != nil {
+		return err
+	}
+	if err = c.touchTx(true); err != nil {
+		return err
+	}
+
+	matched, next, exhausted := c.matchExpectation(func(e expectation) bool {
+		_, ok := e.(*ExpectedCommit)
+		return ok
+	}, nil)
+	if next != nil {
+		return failf(handle, "call to commit transaction, was not expected, next expectation is: %s", lockedString(next))
 	}
 
+	expected, _ := matched.(*ExpectedCommit)
 	if expected == nil {
 		if c.requireExpectations {
 			msg := "call to commit transaction was not expected"
-			if fulfilled == len(c.expected) {
+			if exhausted {
 				msg = "all expectations were already fulfilled, " + msg
 			}
-			return fmt.Errorf(msg)
+			return failf(handle, msg)
 		}
+		c.warnf("call to commit transaction was not expected, tolerated since expectations are not required")
 	} else {
-		expected.triggered = true
+		expected.trigger()
 		expected.Unlock()
 		err = expected.err
 	}
@@ -475,38 +2007,40 @@ func (c *sqlmock) Commit() (err error) {
 }
 
 // Rollback meets http://golang.org/pkg/database/sql/driver/#Tx
-func (c *sqlmock) Rollback() (err error) {
-	var expected *ExpectedRollback
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+func (c *sqlmock) Rollback() error {
+	return c.rollback("")
+}
 
-		if expected, ok = next.(*ExpectedRollback); ok {
-			break
-		}
+func (c *sqlmock) rollback(handle string) (err error) {
+	defer func() { c.emitResult(TxRolledBack, TxFailed, handle, "", nil, err) }()
 
-		next.Unlock()
-		if c.ordered {
-			return fmt.Errorf("call to rollback transaction, was not expected, next expectation is: %s", next)
-		}
+	if err = c.guard(handle); err != nil {
+		return err
+	}
+	if err = c.touchTx(true); err != nil {
+		return err
+	}
+
+	matched, next, exhausted := c.matchExpectation(func(e expectation) bool {
+		_, ok := e.(*ExpectedRollback)
+		return ok
+	}, nil)
+	if next != nil {
+		return failf(handle, "call to rollback transaction, was not expected, next expectation is: %s", lockedString(next))
 	}
 
+	expected, _ := matched.(*ExpectedRollback)
 	if expected == nil {
 		if c.requireExpectations {
 			msg := "call to rollback transaction was not expected"
-			if fulfilled == len(c.expected) {
+			if exhausted {
 				msg = "all expectations were already fulfilled, " + msg
 			}
-			return fmt.Errorf(msg)
+			return failf(handle, msg)
 		}
+		c.warnf("call to rollback transaction was not expected, tolerated since expectations are not required")
 	} else {
-		expected.triggered = true
+		expected.trigger()
 		expected.Unlock()
 		err = expected.err
 	}
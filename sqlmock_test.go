@@ -2,7 +2,9 @@ package sqlmock
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -387,6 +389,7 @@ func TestWrongExpectations(t *testing.T) {
 	}
 	defer db.Close()
 
+	mock.MatchExpectationsInOrder(true)
 	mock.ExpectBegin()
 
 	rs1 := NewRows([]string{"id", "title"}).FromCSVString("5,hello world")
@@ -569,11 +572,14 @@ func TestArgumentReflectValueTypeError(t *testing.T) {
 
 	rs := NewRows([]string{"id"}).AddRow(1)
 
+	// in order, the next expectation reports why it does not match,
+	// instead of panicking on comparison of a float with an int
+	mock.MatchExpectationsInOrder(true)
 	mock.ExpectQuery("SELECT (.+) FROM sales").WithArgs(5.5).WillReturnRows(rs)
 
 	_, err = db.Query("SELECT * FROM sales WHERE x = ?", 5)
-	if err == nil {
-		t.Error("Expected error, but got none")
+	if err == nil || !strings.Contains(err.Error(), "argument 0 differs by type") {
+		t.Errorf("expected an error about the argument type, but got: %v", err)
 	}
 }
 
@@ -658,3 +664,883 @@ func ExampleSqlmock_goroutines() {
 	}
 	// Output:
 }
+
+func TestUnorderedMatchPolicies(t *testing.T) {
+	t.Parallel()
+	cases := map[MatchPolicy]string{
+		MatchFirst:      "generic",
+		MatchBest:       "specific",
+		MatchMostRecent: "recent",
+	}
+
+	for policy, expected := range cases {
+		db, mock, err := New()
+		if err != nil {
+			t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.MatchExpectationsInOrder(false)
+		mock.MatchExpectationsBy(policy)
+
+		mock.ExpectQuery("SELECT").
+			WillReturnRows(NewRows([]string{"name"}).AddRow("generic"))
+		mock.ExpectQuery("SELECT (.+) FROM users WHERE id = ?").
+			WithArgs(1).
+			WillReturnRows(NewRows([]string{"name"}).AddRow("specific"))
+		mock.ExpectQuery("SELECT").
+			WillReturnRows(NewRows([]string{"name"}).AddRow("recent"))
+
+		var name string
+		if err = db.QueryRow("SELECT name FROM users WHERE id = ?", 1).Scan(&name); err != nil {
+			t.Errorf("error '%s' was not expected while querying row", err)
+		}
+
+		if name != expected {
+			t.Errorf("expected policy %d to match '%s' expectation, but it matched '%s'", policy, expected, name)
+		}
+		db.Close()
+	}
+}
+
+func TestBestMatchPolicyTieBreak(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(false)
+	mock.MatchExpectationsBy(MatchBest)
+
+	mock.ExpectExec("UPDATE users").WithArgs(1).WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE users").WithArgs(1).WillReturnResult(NewResult(0, 2))
+
+	for _, expected := range []int64{1, 2} {
+		res, err := db.Exec("UPDATE users SET name = 'x' WHERE id = ?", 1)
+		if err != nil {
+			t.Errorf("error '%s' was not expected while updating", err)
+			continue
+		}
+		if affected, _ := res.RowsAffected(); affected != expected {
+			t.Errorf("expected equally specific expectations to be matched in registration order, but got %d affected rows instead of %d", affected, expected)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestUnexpectedCallHintsClosestExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	mock.ExpectExec("DELETE FROM orders").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO users\\(name\\)").WithArgs("bob").WillReturnResult(NewResult(1, 1))
+
+	_, err = db.Exec("INSERT INTO user(name) VALUES (?)", "bob")
+	if err == nil {
+		t.Fatal("an error was expected since query does not match any expectation")
+	}
+
+	if !strings.Contains(err.Error(), "did you mean: ExpectedExec => expecting Exec which:\n  - matches sql: 'INSERT INTO users") {
+		t.Errorf("expected error to hint the closest insert expectation, but got: %s", err)
+	}
+}
+
+func TestToleratedCallHintsClosestExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT name FROM users")
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(NewRows([]string{"name"}))
+
+	stmt, err := db.Prepare("SELECT nmae FROM users")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected, since expectations are not required", err)
+	}
+	stmt.Close()
+
+	rows, err := db.Query("SELECT nmae FROM users")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected, since expectations are not required", err)
+	}
+	rows.Close()
+
+	warnings := mock.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, but got: %v", warnings)
+	}
+	if !strings.HasPrefix(warnings[0], "call to Prepare 'SELECT nmae FROM users' query was not expected, tolerated since expectations are not required, did you mean: ExpectedPrepare => expecting Prepare statement which:\n  - matches sql: 'SELECT name FROM users'") {
+		t.Errorf("expected the warning to hint the closest prepare expectation, but got: %s", warnings[0])
+	}
+	if !strings.HasPrefix(warnings[1], "call to query 'SELECT nmae FROM users' with args [] was not expected, tolerated since expectations are not required, did you mean: ExpectedQuery => expecting Query or QueryRow which:\n  - matches sql: 'SELECT name FROM users'") {
+		t.Errorf("expected the warning to hint the closest query expectation, but got: %s", warnings[1])
+	}
+
+	mock.RequireExpectations(true)
+	_, err = db.Prepare("SELECT nmae FROM users")
+	if err == nil || !strings.Contains(err.Error(), "was not expected, did you mean: ExpectedPrepare") {
+		t.Errorf("expected the error to hint the closest prepare expectation, but got: %v", err)
+	}
+}
+
+func TestQueryRowExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQueryRow("SELECT (.+) FROM users").
+		WillReturnRows(NewRows([]string{"name"}).AddRow("bob").AddRow("alice"))
+
+	var name string
+	if err = db.QueryRow("SELECT name FROM users").Scan(&name); err != nil {
+		t.Errorf("error '%s' was not expected while scanning row", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestQueryRowExpectationViolations(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQueryRow("SELECT (.+) FROM users").
+		WillReturnRows(NewRows([]string{"name"}).AddRow("bob").AddRow("alice"))
+
+	rows, err := db.Query("SELECT name FROM users")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying", err)
+	}
+	rows.Next()
+
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.HasPrefix(err.Error(), "expected rows to be closed") {
+		t.Errorf("expected an error since rows were not closed, but got: %v", err)
+	}
+
+	for rows.Next() {
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.HasPrefix(err.Error(), "expected at most one row to be fetched, but 2 rows were fetched") {
+		t.Errorf("expected an error since two rows were fetched, but got: %v", err)
+	}
+}
+
+func TestIdleInTransactionTimeout(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.IdleInTransactionTimeout(10 * time.Second)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE orders").WillReturnResult(NewResult(0, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	mock.Clock().Advance(5 * time.Second)
+	if _, err = tx.Exec("UPDATE users SET active = 1"); err != nil {
+		t.Errorf("error '%s' was not expected, since transaction was idle within timeout", err)
+	}
+
+	mock.Clock().Advance(11 * time.Second)
+	if _, err = tx.Exec("UPDATE orders SET active = 1"); err != ErrIdleInTransactionTimeout {
+		t.Errorf("expected idle in transaction timeout error, but got: %v", err)
+	}
+
+	if err = tx.Commit(); err != ErrIdleInTransactionTimeout {
+		t.Errorf("expected transaction to be unusable after timeout, but commit returned: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Errorf("was expecting an error since orders update was not triggered")
+	}
+}
+
+func TestDeadlockScenario(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(false)
+	first, second := mock.ExpectDeadlock("UPDATE accounts (.+) WHERE id = 2", "UPDATE accounts (.+) WHERE id = 1")
+	first.WillReturnResult(NewResult(0, 1))
+	second.WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE accounts").WillReturnResult(NewResult(0, 1)) // retry of a victim
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for _, id := range []int{1, 2} {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			_, err := db.Exec(fmt.Sprintf("UPDATE accounts SET balance = 0 WHERE id = %d", id))
+			errs <- err
+		}(id)
+	}
+	wg.Wait()
+	close(errs)
+
+	var victims int
+	for err := range errs {
+		switch err {
+		case ErrDeadlock:
+			victims++
+		case nil:
+		default:
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+
+	if victims != 1 {
+		t.Fatalf("expected exactly one deadlock victim, but got %d", victims)
+	}
+
+	if _, err = db.Exec("UPDATE accounts SET balance = 0 WHERE id = 3"); err != nil {
+		t.Errorf("error '%s' was not expected while retrying", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestDeadlockWithoutConflictingStatement(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	first, _ := mock.ExpectDeadlock("UPDATE accounts (.+) WHERE id = 2", "UPDATE accounts (.+) WHERE id = 1")
+	first.WillReturnResult(NewResult(0, 1))
+	first.deadlock.timeout = 10 * time.Millisecond
+
+	_, err = db.Exec("UPDATE accounts SET balance = 0 WHERE id = 2")
+	if err == nil || err.Error() != "deadlock was expected, but the conflicting statement, which matches [UPDATE accounts (.+) WHERE id = 1], was not executed within 10ms" {
+		t.Errorf("expected an error about the missing conflicting statement, but got: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected the conflicting statement not to be met")
+	}
+}
+
+func TestConcurrentExpectationsWereMet(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	const calls = 20
+	for i := 0; i < calls; i++ {
+		mock.ExpectExec("UPDATE counters").WillReturnResult(NewResult(0, 1))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := db.Exec("UPDATE counters SET n = n + 1"); err != nil {
+				t.Errorf("error was not expected: %s", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			mock.ExpectationsWereMet() // may or may not be met yet
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 2; i++ {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expections: %s", err)
+		}
+	}
+}
+
+func TestUnorderedExpectationPriority(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectExec("DELETE").WillReturnResult(NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM sessions").Priority(10).WillReturnResult(NewResult(0, 10))
+	mock.ExpectExec("DELETE FROM sessions").Priority(5).WillReturnResult(NewResult(0, 5))
+
+	for _, expected := range []int64{10, 5, 0} {
+		res, err := db.Exec("DELETE FROM sessions")
+		if err != nil {
+			t.Fatalf("error '%s' was not expected while deleting", err)
+		}
+		if affected, _ := res.RowsAffected(); affected != expected {
+			t.Errorf("expected expectation with %d rows affected to be matched, but got %d", expected, affected)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestReusableExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectQuery("SELECT 1").Reusable().WillReturnRows(NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	keepalive := func() {
+		rows, err := db.Query("SELECT 1")
+		if err != nil {
+			t.Errorf("error '%s' was not expected on keepalive", err)
+			return
+		}
+		rows.Close()
+	}
+
+	keepalive()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	keepalive()
+	if _, err = tx.Exec("UPDATE users SET active = 1"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+	keepalive()
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while commiting", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestExecFailingTxExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectBegin()
+	mock.ExpectExecFailingTx("INSERT INTO users", fmt.Errorf("duplicate key")).WithArgs("bob")
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	if _, err = tx.Exec("INSERT INTO users (name) VALUES (?)", "bob"); err == nil || err.Error() != "duplicate key" {
+		t.Errorf("expected duplicate key error, but got: %v", err)
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Errorf("error '%s' was not expected while rolling back", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestRowsMiddleware(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.UseRowsMiddleware(
+		func(call CallInfo, rs Rows) Rows {
+			masked := NewRows(rs.Columns())
+			dest := make([]driver.Value, len(rs.Columns()))
+			for rs.Next(dest) == nil {
+				dest[1] = "***"
+				masked.AddRow(dest...)
+			}
+			return masked
+		},
+		func(call CallInfo, rs Rows) Rows {
+			return rs.AddRow(0, call.Query)
+		},
+	)
+
+	expected := NewRows([]string{"id", "email"}).AddRow(1, "bob@example.com")
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(expected)
+
+	rs, err := db.Query("SELECT id, email FROM users")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying", err)
+	}
+	defer rs.Close()
+
+	var scanned []string
+	for rs.Next() {
+		var id int
+		var email string
+		if err = rs.Scan(&id, &email); err != nil {
+			t.Errorf("error '%s' was not expected while scanning", err)
+		}
+		scanned = append(scanned, fmt.Sprintf("%d:%s", id, email))
+	}
+
+	if s := strings.Join(scanned, ","); s != "1:***,0:SELECT id, email FROM users" {
+		t.Errorf("expected middleware to mask email and append audit row, but got: %s", s)
+	}
+
+	if len(expected.(*rows).rows) != 1 {
+		t.Errorf("expected middleware not to modify the expected rows")
+	}
+}
+
+func TestReadOnlyTransaction(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin().ReadOnly()
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	rows, err := tx.Query("SELECT id FROM users")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while reading in read-only transaction", err)
+	} else {
+		rows.Close()
+	}
+
+	_, err = tx.Exec("update users SET name = ?", "bob")
+	if err == nil || err.Error() != "ERROR: cannot execute UPDATE in a read-only transaction" {
+		t.Errorf("a read-only transaction error was expected, but got: %v", err)
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Errorf("error '%s' was not expected while rolling back a transaction", err)
+	}
+
+	// the following transaction is not read-only
+	if tx, err = db.Begin(); err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err = tx.Exec("UPDATE users SET name = ?", "bob"); err != nil {
+		t.Errorf("error '%s' was not expected while writing in read-write transaction", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while commiting a transaction", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestRequireTransactions(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireTransactions()
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rows, err := db.Query("SELECT id FROM users")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while reading outside of a transaction", err)
+	} else {
+		rows.Close()
+	}
+
+	_, err = db.Exec("UPDATE users SET name = ?", "bob")
+	if err == nil || !strings.Contains(err.Error(), "was not expected outside of a transaction") {
+		t.Errorf("an error of a write outside of a transaction was expected, but got: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err = tx.Exec("UPDATE users SET name = ?", "bob"); err != nil {
+		t.Errorf("error '%s' was not expected while writing in a transaction", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while commiting a transaction", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected the write outside of a transaction not to meet its expectation")
+	}
+}
+
+func TestExecReturningResultNoRows(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("CREATE TABLE users").WillReturnResultNoRows()
+
+	res, err := db.Exec("CREATE TABLE users (id INT)")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while creating a table", err)
+	}
+
+	if _, err = res.RowsAffected(); err == nil {
+		t.Error("an error was expected when getting affected rows of DDL statement")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestExtensionInterfaces(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	var common SqlmockCommon = mock
+	var sim Simulator
+	if !As(common, &sim) {
+		t.Fatal("expected mock to implement Simulator extension")
+	}
+	if sim.Clock() != mock.Clock() {
+		t.Error("expected extension to be the same mock")
+	}
+
+	var reporter Reporter
+	if !As(common, &reporter) {
+		t.Fatal("expected mock to implement Reporter extension")
+	}
+
+	var closer interface {
+		Close() error
+	}
+	if As(struct{ SqlmockCommon }{common}, &closer) {
+		t.Error("expected wrapped core mock not to implement an unrelated interface")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected As to panic when target is not a pointer to an interface")
+		}
+	}()
+	As(common, sim)
+}
+
+func TestDeferredRollbackAfterCommit(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.RequireExpectations(true)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	update := func() error {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err = tx.Exec("UPDATE users SET name = ?", "bob"); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if err = update(); err != nil {
+		t.Errorf("error '%s' was not expected while updating in a transaction", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestTransactionIsDoneAfterCommit(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	// drive the transaction directly, as a driver wrapper would
+	tx, err := mock.(*sqlmock).Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while commiting a transaction", err)
+	}
+	if err = tx.Commit(); err != sql.ErrTxDone {
+		t.Errorf("expected sql.ErrTxDone when commiting a finished transaction, but got: %v", err)
+	}
+	if err = tx.Rollback(); err != sql.ErrTxDone {
+		t.Errorf("expected sql.ErrTxDone when rolling back a finished transaction, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected the second transaction expectations not to be matched by the finished one")
+	}
+}
+
+func TestNestedBeginIsRejected(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	// begin twice on the same connection, bypassing database/sql
+	tx, err := mock.(driver.Conn).Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err = mock.(driver.Conn).Begin(); err != ErrTxInProgress {
+		t.Errorf("expected ErrTxInProgress when beginning a nested transaction, but got: %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while commiting a transaction", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected the second begin not to be matched by the nested one")
+	}
+}
+
+func TestNestedBeginWithSeveralConnections(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectRollback()
+	mock.ExpectRollback()
+
+	// database/sql begins the second transaction on another connection
+	tx1, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	tx2, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction on another connection", err)
+	}
+
+	// connections share the mock, so that a nested begin is not told apart
+	// from a begin on another connection, once several connections are open
+	tx3, err := mock.(driver.Conn).Begin()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected, since several connections are open", err)
+	} else {
+		tx3.Rollback()
+	}
+	tx2.Rollback()
+	tx1.Rollback()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestWithoutQueryNormalization(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.WithoutQueryNormalization()
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectExec("^COPY users FROM STDIN\n1\tjohn\n$").WillReturnResult(NewResult(0, 1))
+
+	if err := mock.Validate(); err != nil {
+		t.Errorf("error '%s' was not expected, since queries are not stripped", err)
+	}
+
+	if _, err := db.Exec("COPY users FROM STDIN\n1\tjohn\n"); err != nil {
+		t.Errorf("error '%s' was not expected, since the query should be matched as given", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestFluentExpectations(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.EXPECT().Begin()
+	mock.EXPECT().Query("SELECT name FROM users").WithArgs(5).Return(NewRows([]string{"name"}).AddRow("john"))
+	mock.EXPECT().Exec("UPDATE users").WithArgs("john", 5).Times(2).Return(NewResult(0, 1))
+	mock.EXPECT().Commit().Return(fmt.Errorf("commit failed"))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	var name string
+	if err := tx.QueryRow("SELECT name FROM users WHERE id = ?", 5).Scan(&name); err != nil || name != "john" {
+		t.Errorf("expected to select john, but got %q and error: %v", name, err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := tx.Exec("UPDATE users SET name = ? WHERE id = ?", "john", 5); err != nil {
+			t.Errorf("error '%s' was not expected while updating a user", err)
+		}
+	}
+	if err := tx.Commit(); err == nil || err.Error() != "commit failed" {
+		t.Errorf("expected the mocked commit error, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestMatchInOrderOnly(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchInOrderOnly(OrderStatements)
+	mock.RequireExpectations(true)
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// transaction markers are not ordered relative to statements
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	if _, err := tx.Exec("INSERT INTO users(id) VALUES(1)"); err != nil {
+		t.Errorf("error '%s' was not expected while inserting", err)
+	}
+	if _, err := tx.Exec("INSERT INTO orders(id) VALUES(1)"); err != nil {
+		t.Errorf("error '%s' was not expected while inserting", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while committing", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+
+	// statements are still ordered
+	mock.ExpectExec("DELETE FROM orders").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+
+	_, err = db.Exec("DELETE FROM users")
+	if err == nil || err.Error() != "exec query 'DELETE FROM users', does not match regex 'DELETE FROM orders'" {
+		t.Errorf("expected statements to be matched in order, but got: %v", err)
+	}
+}
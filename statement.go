@@ -1,13 +1,23 @@
 package sqlmock
 
 import (
+	"context"
 	"database/sql/driver"
 )
 
 type statement struct {
-	conn  *sqlmock
-	query string
-	err   error
+	conn     *sqlmock
+	handle   string
+	query    string
+	err      error
+	prepared *ExpectedPrepare
+}
+
+// executed records an execution of the prepared statement
+func (stmt *statement) executed(args []driver.Value) {
+	if stmt.prepared != nil {
+		stmt.prepared.executed(args)
+	}
 }
 
 func (stmt *statement) Close() error {
@@ -19,9 +29,11 @@ func (stmt *statement) NumInput() int {
 }
 
 func (stmt *statement) Exec(args []driver.Value) (driver.Result, error) {
-	return stmt.conn.Exec(stmt.query, args)
+	stmt.executed(args)
+	return stmt.conn.exec(context.Background(), stmt.handle, stmt.query, args)
 }
 
 func (stmt *statement) Query(args []driver.Value) (driver.Rows, error) {
-	return stmt.conn.Query(stmt.query, args)
+	stmt.executed(args)
+	return stmt.conn.query(context.Background(), stmt.handle, stmt.query, args)
 }
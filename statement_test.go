@@ -0,0 +1,166 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestPreparedStatementCache(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	prep := mock.ExpectPrepare("UPDATE users SET visits").WithKey("users.visit").WillBeExecutedTimes(3)
+	prep.ExpectExec().WillReturnResult(NewResult(0, 1)).Reusable()
+
+	stmt, err := db.Prepare("UPDATE users SET visits = visits + 1 WHERE id = ?")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err = stmt.Exec(i); err != nil {
+			t.Errorf("error '%s' was not expected while executing a prepared statement", err)
+		}
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err == nil || err.Error() != "prepared statement 'users.visit' was executed 2 times, but expected to be executed 3 times" {
+		t.Errorf("expected an error about prepared statement executions, but got: %v", err)
+	}
+
+	if _, err = stmt.Exec(3); err != nil {
+		t.Errorf("error '%s' was not expected while executing a prepared statement", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+
+	_, err = db.Prepare("UPDATE users SET visits = visits + 1 WHERE id = ?")
+	if err == nil || !strings.Contains(err.Error(), "statement 'users.visit' with query 'UPDATE users SET visits = visits + 1 WHERE id = ?' was prepared again") {
+		t.Errorf("expected an error since statement should be cached, but got: %v", err)
+	}
+}
+
+func TestPrepareCounts(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT name FROM users")
+	mock.ExpectPrepare("SELECT name FROM users")
+	mock.ExpectPrepare("DELETE FROM users")
+
+	for _, query := range []string{"SELECT name FROM users", "SELECT name\n  FROM users", "DELETE FROM users"} {
+		stmt, err := db.Prepare(query)
+		if err != nil {
+			t.Fatalf("error '%s' was not expected while preparing a statement", err)
+		}
+		stmt.Close()
+	}
+
+	counts := mock.PrepareCounts()
+	if counts["SELECT name FROM users"] != 2 || counts["DELETE FROM users"] != 1 || len(counts) != 2 {
+		t.Errorf("unexpected prepare counts: %v", counts)
+	}
+
+	if err = mock.PreparedAtMost(2); err != nil {
+		t.Errorf("error '%s' was not expected since no query was prepared more than twice", err)
+	}
+	err = mock.PreparedAtMost(1)
+	if err == nil || err.Error() != "queries were prepared more than 1 times: 'SELECT name FROM users' (2)" {
+		t.Errorf("expected an error listing redundantly prepared query, but got: %v", err)
+	}
+}
+
+func TestPreparedStatementArgSets(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	prep := mock.ExpectPrepare("INSERT INTO users").WillBeExecutedWithArgSets(
+		[]driver.Value{1, "john"},
+		[]driver.Value{2, "mark"},
+		[]driver.Value{2, "mark"},
+	)
+	prep.ExpectExec().WillReturnResult(NewResult(0, 1)).Reusable()
+
+	stmt, err := db.Prepare("INSERT INTO users (id, name) VALUES (?, ?) ON CONFLICT DO NOTHING")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	defer stmt.Close()
+
+	for _, args := range [][]interface{}{{2, "mark"}, {1, "john"}} {
+		if _, err = stmt.Exec(args...); err != nil {
+			t.Errorf("error '%s' was not expected while executing a prepared statement", err)
+		}
+	}
+
+	if err = mock.ExpectationsWereMet(); err == nil || !strings.HasPrefix(err.Error(), "prepared statement 'INSERT INTO users' was executed with argument sets") {
+		t.Errorf("expected an error about prepared statement argument sets, but got: %v", err)
+	}
+
+	if _, err = stmt.Exec(2, "mark"); err != nil {
+		t.Errorf("error '%s' was not expected while executing a prepared statement", err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestPreparedStatementsByKey(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sel := mock.ExpectPrepare("SELECT name FROM users").WithKey("users.select").WillBeExecutedTimes(1)
+	sel.ExpectQuery().WillReturnRows(NewRows([]string{"name"}).AddRow("bob"))
+	upd := mock.ExpectPrepare("UPDATE users SET visits").WithKey("users.visit").WillBeExecutedTimes(2)
+	upd.ExpectExec().WillReturnResult(NewResult(0, 1)).Times(2)
+
+	// prepared in the other order, than they were expected
+	update, err := db.Prepare("UPDATE users SET visits = visits + 1 WHERE id = ?")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	defer update.Close()
+	selectName, err := db.Prepare("SELECT name FROM users WHERE id = ?")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	defer selectName.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err = update.Exec(i); err != nil {
+			t.Errorf("error '%s' was not expected while executing a prepared statement", err)
+		}
+	}
+	var name string
+	if err = selectName.QueryRow(1).Scan(&name); err != nil {
+		t.Errorf("error '%s' was not expected while querying a prepared statement", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+
+	_, err = db.Prepare("UPDATE users SET visits = visits + 1 WHERE id = ?")
+	if err == nil || !strings.Contains(err.Error(), "statement 'users.visit' with query") {
+		t.Errorf("expected an error since the update statement should be cached, but got: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package sqlmock
+
+func (c *sqlmock) Step(name string, expect func()) {
+	c.Lock()
+	prev, prevIndex := c.step, c.stepIndex
+	c.steps++
+	c.step, c.stepIndex = name, c.steps
+	c.Unlock()
+
+	defer func() {
+		c.Lock()
+		c.step, c.stepIndex = prev, prevIndex
+		c.Unlock()
+	}()
+	expect()
+}
+
+func (c *sqlmock) StrictSteps() {
+	c.Lock()
+	c.strictSteps = true
+	c.Unlock()
+}
+
+// stepOf returns the number of the step the expectation was queued
+// in, counted from 1, or 0 if it was not queued in a step
+func stepOf(e expectation) int {
+	if a, ok := e.(Expected); ok {
+		return a.common().stepIndex
+	}
+	return 0
+}
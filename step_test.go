@@ -0,0 +1,66 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStepIsToldByUnmetExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.Step("create user", func() {
+		mock.ExpectExec("INSERT INTO users").WillReturnResult(NewResult(1, 1))
+	})
+	mock.Step("grant role", func() {
+		mock.ExpectExec("INSERT INTO roles").WillReturnResult(NewResult(1, 1))
+	})
+
+	if _, err := db.Exec("INSERT INTO users(name) VALUES(?)", "bob"); err != nil {
+		t.Errorf("an error '%s' was not expected while creating a user", err)
+	}
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.HasSuffix(err.Error(), "is pending, in step 'grant role'") {
+		t.Errorf("expected an error telling the step, which was not met, but got: %v", err)
+	}
+}
+
+func TestStrictSteps(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.StrictSteps()
+	mock.Step("create user", func() {
+		mock.ExpectExec("INSERT INTO users").WillReturnResult(NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO profiles").WillReturnResult(NewResult(1, 1))
+	})
+	mock.Step("grant role", func() {
+		mock.ExpectExec("INSERT INTO roles").WillReturnResult(NewResult(1, 1))
+	})
+
+	if _, err := db.Exec("INSERT INTO users(name) VALUES(?)", "bob"); err != nil {
+		t.Errorf("an error '%s' was not expected while creating a user", err)
+	}
+	_, err = db.Exec("INSERT INTO roles(name) VALUES(?)", "admin")
+	if err == nil || !strings.HasPrefix(err.Error(), "call to exec query 'INSERT INTO roles(name) VALUES(?)' with args [admin], was not expected, next expectation is: ExpectedExec => expecting Exec which:\n  - matches sql: 'INSERT INTO profiles'") {
+		t.Errorf("expected an error about the step, which has not completed, but got: %v", err)
+	}
+
+	if _, err := db.Exec("INSERT INTO profiles(user_id) VALUES(?)", 1); err != nil {
+		t.Errorf("an error '%s' was not expected while creating a profile", err)
+	}
+	if _, err := db.Exec("INSERT INTO roles(name) VALUES(?)", "admin"); err != nil {
+		t.Errorf("an error '%s' was not expected, once the previous step completed", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
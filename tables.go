@@ -0,0 +1,118 @@
+package sqlmock
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+func (c *sqlmock) AllowTables(tables ...string) {
+	c.Lock()
+	if c.allowedTables == nil {
+		c.allowedTables = make(map[string]bool)
+	}
+	for _, t := range tables {
+		c.allowedTables[strings.ToLower(t)] = true
+	}
+	c.Unlock()
+}
+
+// checkTables verifies that the stripped query references only
+// allowed tables, if any were allowed
+func (c *sqlmock) checkTables(query string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.allowedTables == nil {
+		return nil
+	}
+
+	for _, table := range statementTables(query) {
+		name := strings.ToLower(table)
+		if c.allowedTables[name] {
+			continue
+		}
+		// schema qualified name is allowed by its unqualified part
+		if i := strings.LastIndex(name, "."); i != -1 && c.allowedTables[name[i+1:]] {
+			continue
+		}
+		return fmt.Errorf("query '%s' references table '%s', which is not allowed", query, table)
+	}
+	return nil
+}
+
+var sqlTokenRe = regexp.MustCompile(
+	"'(?:[^']|'')*'|" + // string literal
+		"(?:\"[^\"]*\"|`[^`]*`|\\[[^\\]]*\\]|[\\w$]+)(?:\\.(?:\"[^\"]*\"|`[^`]*`|\\[[^\\]]*\\]|[\\w$]+))*|" + // identifier
+		"[(),;]")
+
+// keywords which may not be a table alias
+var sqlClauseKeywords = map[string]bool{
+	"WHERE": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true,
+	"CROSS": true, "NATURAL": true, "ON": true, "USING": true, "GROUP": true, "ORDER": true,
+	"LIMIT": true, "OFFSET": true, "HAVING": true, "WINDOW": true, "UNION": true, "EXCEPT": true,
+	"INTERSECT": true, "SET": true, "VALUES": true, "RETURNING": true, "FOR": true, "SELECT": true,
+}
+
+// functions which use FROM keyword within their arguments
+var sqlFromFunctions = map[string]bool{
+	"EXTRACT": true, "SUBSTRING": true, "TRIM": true, "OVERLAY": true, "POSITION": true,
+}
+
+// statementTables heuristically parses the names of tables, which
+// are referenced by the sql statement. Quotes are removed from names.
+func statementTables(query string) (tables []string) {
+	tokens := sqlTokenRe.FindAllString(query, -1)
+	unquote := strings.NewReplacer("\"", "", "`", "", "[", "", "]", "")
+	isName := func(i int) bool {
+		return i < len(tokens) && tokens[i][0] != '\'' && !strings.ContainsAny(tokens[i][:1], "(),;")
+	}
+
+	var parens []bool // whether the parenthesis belong to a function using FROM
+	for i, tok := range tokens {
+		switch upper := strings.ToUpper(tok); upper {
+		case "(":
+			parens = append(parens, i > 0 && sqlFromFunctions[strings.ToUpper(tokens[i-1])])
+		case ")":
+			if len(parens) > 0 {
+				parens = parens[:len(parens)-1]
+			}
+		case "FROM", "JOIN", "INTO", "UPDATE", "TABLE":
+			if upper == "FROM" && len(parens) > 0 && parens[len(parens)-1] {
+				continue
+			}
+
+			j := i + 1
+			for ; isName(j); j++ {
+				switch strings.ToUpper(tokens[j]) {
+				case "ONLY", "LATERAL", "IF", "NOT", "EXISTS":
+					continue
+				}
+				break
+			}
+
+			for isName(j) {
+				if (upper == "FROM" || upper == "JOIN") && j+1 < len(tokens) && tokens[j+1] == "(" {
+					break // a function call
+				}
+				tables = append(tables, unquote.Replace(tokens[j]))
+				if upper != "FROM" {
+					break
+				}
+
+				// skip an alias and continue with the next table in the list
+				k := j + 1
+				if isName(k) && strings.ToUpper(tokens[k]) == "AS" {
+					k += 2
+				} else if isName(k) && !sqlClauseKeywords[strings.ToUpper(tokens[k])] {
+					k++
+				}
+				if k >= len(tokens) || tokens[k] != "," {
+					break
+				}
+				j = k + 1
+			}
+		}
+	}
+	return
+}
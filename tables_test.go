@@ -0,0 +1,67 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAllowTables(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.AllowTables("users", "Orders")
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT INTO public.orders").WillReturnResult(NewResult(1, 1))
+
+	rows, err := db.Query("SELECT u.id FROM users u JOIN ORDERS o ON o.user_id = u.id")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while querying allowed tables", err)
+	} else {
+		rows.Close()
+	}
+
+	if _, err = db.Exec("INSERT INTO public.orders (user_id) VALUES (?)", 1); err != nil {
+		t.Errorf("error '%s' was not expected while inserting into allowed table", err)
+	}
+
+	_, err = db.Query("SELECT * FROM users JOIN payments USING (id)")
+	if err == nil || !strings.Contains(err.Error(), "references table 'payments', which is not allowed") {
+		t.Errorf("an error was expected since payments table is not allowed, but got: %v", err)
+	}
+
+	if _, err = db.Prepare("DELETE FROM payments"); err == nil {
+		t.Error("an error was expected when preparing a statement referencing not allowed table")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestStatementTables(t *testing.T) {
+	assert := func(query string, expected ...string) {
+		tables := statementTables(query)
+		if strings.Join(tables, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected '%s' to reference tables %v, but got %v", query, expected, tables)
+		}
+	}
+
+	assert("SELECT 1")
+	assert("SELECT * FROM users WHERE id = ?", "users")
+	assert("SELECT u.id FROM users u JOIN orders AS o ON o.user_id = u.id", "users", "orders")
+	assert("SELECT * FROM users u, public.orders o, items WHERE 1", "users", "public.orders", "items")
+	assert(`SELECT * FROM "Users" LEFT JOIN `+"`orders`"+` USING (id)`, "Users", "orders")
+	assert("SELECT EXTRACT(YEAR FROM created) FROM sales", "sales")
+	assert("SELECT * FROM (SELECT id FROM users) AS sub", "users")
+	assert("SELECT * FROM generate_series(1, 10)")
+	assert("SELECT 'from nowhere' FROM t", "t")
+	assert("INSERT INTO users (name) VALUES (?)", "users")
+	assert("UPDATE ONLY users SET name = ?", "users")
+	assert("DELETE FROM sessions WHERE expired", "sessions")
+	assert("CREATE TABLE IF NOT EXISTS audit (id INT)", "audit")
+	assert("TRUNCATE TABLE logs", "logs")
+}
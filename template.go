@@ -0,0 +1,76 @@
+package sqlmock
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// renderTemplate renders text template tmpl with data
+func renderTemplate(tmpl string, data interface{}) string {
+	t, err := template.New("rows").Parse(tmpl)
+	if err != nil {
+		panic(fmt.Sprintf("Expected rows template to be valid, but got: %s", err))
+	}
+
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		panic(fmt.Sprintf("Expected rows template to render, but got: %s", err))
+	}
+	return buf.String()
+}
+
+func (r *rows) FromCSVTemplate(tmpl string, data interface{}) Rows {
+	return r.FromCSVString(renderTemplate(tmpl, data))
+}
+
+func (r *rows) FromJSONTemplate(tmpl string, data interface{}) Rows {
+	return r.FromJSONString(renderTemplate(tmpl, data))
+}
+
+func (r *rows) FromJSONString(s string) Rows {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var objects []map[string]json.RawMessage
+	if err := dec.Decode(&objects); err != nil {
+		panic(fmt.Sprintf("Expected rows json to be an array of objects, but got: %s", err))
+	}
+
+	for _, obj := range objects {
+		row := make([]driver.Value, len(r.cols))
+		for i, col := range r.cols {
+			raw, ok := obj[col]
+			if !ok {
+				panic(fmt.Sprintf("Expected rows json object to have column '%s', but got %v", col, obj))
+			}
+			row[i] = jsonValue(raw)
+		}
+		r.rows = append(r.rows, row)
+	}
+	return r
+}
+
+// jsonValue converts raw json to a driver value, nested
+// arrays and objects are kept as raw json bytes
+func jsonValue(raw json.RawMessage) driver.Value {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var v interface{}
+	dec.Decode(&v) // the raw message is valid json
+	switch t := v.(type) {
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n
+		}
+		f, _ := t.Float64()
+		return f
+	case string, bool, nil:
+		return t
+	}
+	return []byte(raw)
+}
@@ -0,0 +1,62 @@
+package sqlmock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRowsFromTemplates(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	params := struct {
+		ID      int
+		Created time.Time
+	}{42, time.Date(2023, 2, 1, 10, 0, 0, 0, time.UTC)}
+
+	var users, orders RowsLoader
+	if !As(NewRows([]string{"id", "name", "created"}), &users) || !As(NewRows([]string{"id", "user_id", "total", "paid", "tags"}), &orders) {
+		t.Fatal("expected rows to be built from templates")
+	}
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(users.
+		FromCSVTemplate(`{{.ID}},bob,{{.Created.Format "2006-01-02"}}`, params))
+	mock.ExpectQuery("SELECT (.+) FROM orders").WillReturnRows(orders.
+		FromJSONTemplate(`[{"id": 1, "user_id": {{.ID}}, "total": 9.5, "paid": true, "tags": ["new"]}]`, params))
+
+	var id int
+	var name, created string
+	if err = db.QueryRow("SELECT id, name, created FROM users").Scan(&id, &name, &created); err != nil {
+		t.Errorf("error '%s' was not expected while scanning csv template row", err)
+	} else if id != 42 || name != "bob" || created != "2023-02-01" {
+		t.Errorf("expected csv template to render 42, bob, 2023-02-01, but got %d, %s, %s", id, name, created)
+	}
+
+	var userID int64
+	var total float64
+	var paid bool
+	var tags []byte
+	if err = db.QueryRow("SELECT * FROM orders").Scan(&id, &userID, &total, &paid, &tags); err != nil {
+		t.Errorf("error '%s' was not expected while scanning json template row", err)
+	} else if userID != 42 || total != 9.5 || !paid || string(tags) != `["new"]` {
+		t.Errorf("unexpected json template row: %d, %f, %v, %s", userID, total, paid, tags)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestRowsFromJSONStringMissingColumn(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic when json object has no value for a column")
+		}
+	}()
+	var loader RowsLoader
+	As(NewRows([]string{"id", "name"}), &loader)
+	loader.FromJSONString(`[{"id": 1}]`)
+}
@@ -0,0 +1,101 @@
+package sqlmock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Tenants is a family of mocks, one for every tenant database, for
+// code which opens databases of tenants dynamically, by a data source
+// name it resolves for the tenant. The resolver of such code is
+// replaced by Resolve, while a test queues expectations of every
+// tenant on its Mock:
+//
+//	tenants := sqlmock.NewTenants()
+//	tenants.Mock("acme").ExpectQuery("SELECT (.+) FROM invoices").WillReturnRows(rows)
+//	svc := billing.New(func(tenant string) (*sql.DB, error) {
+//		return sql.Open("sqlmock", tenants.Resolve(tenant))
+//	})
+//	// run the code
+//	if err := tenants.ExpectationsWereMet(); err != nil {
+//		t.Error(err)
+//	}
+//
+// A mock of a tenant is deregistered, once its database is closed, and
+// registered again, when the tenant is resolved again.
+type Tenants struct {
+	mu    sync.Mutex
+	id    int
+	mocks map[string]*sqlmock
+}
+
+// NewTenants creates a family of mocks, which are created for tenants
+// on first use
+func NewTenants() *Tenants {
+	pool.Lock()
+	id := pool.counter
+	pool.counter++
+	pool.Unlock()
+
+	return &Tenants{id: id, mocks: make(map[string]*sqlmock)}
+}
+
+// Mock returns the mock of the tenant, it is created on first use
+func (t *Tenants) Mock(tenant string) Sqlmock {
+	return t.mock(tenant)
+}
+
+// Resolve returns the data source name of the tenant database, which
+// opens the mock of the tenant by sql.Open("sqlmock", dsn)
+func (t *Tenants) Resolve(tenant string) string {
+	c := t.mock(tenant)
+
+	pool.Lock()
+	pool.conns[c.dsn] = c
+	pool.Unlock()
+	return c.dsn
+}
+
+// Names returns names of tenants, which mocks were created, sorted
+func (t *Tenants) Names() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	names := make([]string, 0, len(t.mocks))
+	for tenant := range t.mocks {
+		names = append(names, tenant)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpectationsWereMet verifies expectations of mocks of all tenants,
+// an error lists the tenants, which expectations were not met
+func (t *Tenants) ExpectationsWereMet() error {
+	var problems []string
+	for _, tenant := range t.Names() {
+		if err := t.mock(tenant).ExpectationsWereMet(); err != nil {
+			problems = append(problems, fmt.Sprintf("tenant '%s': %s", tenant, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("expectations of tenants were not met:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// mock returns the mock of the tenant, registering it on first use
+func (t *Tenants) mock(tenant string) *sqlmock {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if c, ok := t.mocks[tenant]; ok {
+		return c
+	}
+
+	c := newMock(fmt.Sprintf("sqlmock_tenants_%d_%s", t.id, tenant))
+	t.mocks[tenant] = c
+	return c
+}
@@ -0,0 +1,42 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestTenants(t *testing.T) {
+	t.Parallel()
+	tenants := NewTenants()
+	tenants.Mock("acme").ExpectQuery("SELECT (.+) FROM invoices").WillReturnRows(NewRows([]string{"total"}).AddRow(10))
+	tenants.Mock("globex").ExpectQuery("SELECT (.+) FROM invoices").WillReturnRows(NewRows([]string{"total"}).AddRow(20))
+	tenants.Mock("initech").ExpectExec("DELETE FROM invoices").WillReturnResult(NewResult(0, 1))
+
+	tenants.Mock("acme").ExpectQuery("SELECT (.+) FROM invoices").WillReturnRows(NewRows([]string{"total"}).AddRow(30))
+
+	totals := make(map[string]int)
+	for _, tenant := range []string{"acme", "globex", "acme"} {
+		db, err := sql.Open("sqlmock", tenants.Resolve(tenant))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening the database of %s", err, tenant)
+		}
+		var total int
+		if err := db.QueryRow("SELECT sum(amount) FROM invoices").Scan(&total); err != nil {
+			t.Errorf("an error '%s' was not expected while querying the database of %s", err, tenant)
+		}
+		totals[tenant] += total
+		db.Close()
+	}
+	if totals["acme"] != 40 || totals["globex"] != 20 {
+		t.Errorf("expected totals of every tenant from its own mock, but got: %v", totals)
+	}
+
+	err := tenants.ExpectationsWereMet()
+	if err == nil || !strings.HasPrefix(err.Error(), "expectations of tenants were not met:\n  - tenant 'initech': there is a remaining expectation which was not matched") {
+		t.Errorf("expected an error about the unmet expectation of initech, but got: %v", err)
+	}
+	if names := strings.Join(tenants.Names(), ","); names != "acme,globex,initech" {
+		t.Errorf("expected mocks of three tenants, but got: %s", names)
+	}
+}
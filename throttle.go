@@ -0,0 +1,46 @@
+package sqlmock
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPoolTimeout is returned for a call, which waited for longer than
+// the timeout for one of the calls in flight to finish, see LimitInFlight
+var ErrPoolTimeout = errors.New("sqlmock: timed out waiting for a connection, all connections are busy")
+
+func (c *sqlmock) LimitInFlight(max int, timeout time.Duration) {
+	var slots chan struct{}
+	if max > 0 {
+		slots = make(chan struct{}, max)
+	}
+
+	c.Lock()
+	c.slots, c.slotTimeout = slots, timeout
+	c.Unlock()
+}
+
+// acquire takes one of the slots, waiting for up to timeout on the
+// clock, if all are taken. Nil slots are not limited.
+func acquire(slots chan struct{}, timeout time.Duration, clock *Clock) error {
+	if slots == nil {
+		return nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if timeout <= 0 {
+		slots <- struct{}{}
+		return nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-clock.After(timeout):
+		return ErrPoolTimeout
+	}
+}
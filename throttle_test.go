@@ -0,0 +1,52 @@
+package sqlmock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimitInFlight(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.LimitInFlight(1, 5*time.Second)
+	mock.ExpectExec("UPDATE slow").WillDelayFor(300 * time.Millisecond).WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE fast").WillReturnResult(NewResult(0, 1))
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := db.Exec("UPDATE slow SET n = 1")
+		slow <- err
+	}()
+	for mock.PeakInFlight() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	fast := make(chan error, 1)
+	go func() {
+		_, err := db.Exec("UPDATE fast SET n = 1")
+		fast <- err
+	}()
+	for mock.Clock().Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	mock.Clock().Advance(5 * time.Second)
+
+	if err := <-fast; err != ErrPoolTimeout {
+		t.Errorf("expected the pool timeout error, but got: %v", err)
+	}
+	if err := <-slow; err != nil {
+		t.Errorf("an error '%s' was not expected for the call in flight", err)
+	}
+
+	if _, err := db.Exec("UPDATE fast SET n = 1"); err != nil {
+		t.Errorf("an error '%s' was not expected, once the call in flight finished", err)
+	}
+	if peak := mock.PeakInFlight(); peak != 1 {
+		t.Errorf("expected at most 1 call in flight, but got %d", peak)
+	}
+}
@@ -0,0 +1,47 @@
+package sqlmock
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrStatementTimeout is returned by postgres for a statement, which
+// ran longer than statement_timeout, see WillTimeoutAfter
+var ErrStatementTimeout = errors.New("ERROR: canceling statement due to statement timeout")
+
+// ErrMaxExecutionTime is returned by mysql for a statement, which ran
+// longer than max_execution_time, see WillTimeoutAfter
+var ErrMaxExecutionTime = errors.New("Error 3024: Query execution was interrupted, maximum statement execution time exceeded")
+
+// WillTimeoutAfter makes the query to fail after the duration, as the
+// server cancels a statement, which runs longer than its timeout. The
+// error is ErrMaxExecutionTime, if the mock simulates mysql by
+// WithServerVersion, otherwise ErrStatementTimeout. If the context
+// of the call is done earlier, the client cancels the statement as
+// for WillDelayFor and the error of the context is returned instead,
+// so that a server timeout could be told apart from a client
+// cancellation.
+func (e *ExpectedQuery) WillTimeoutAfter(duration time.Duration) *ExpectedQuery {
+	e.timeout, e.timesOut = duration, true
+	return e
+}
+
+// WillTimeoutAfter makes the statement to fail after the duration, as
+// the server cancels a statement, which runs longer than its timeout,
+// see ExpectedQuery.WillTimeoutAfter
+func (e *ExpectedExec) WillTimeoutAfter(duration time.Duration) *ExpectedExec {
+	e.timeout, e.timesOut = duration, true
+	return e
+}
+
+// timeoutError returns the error of a timed out statement
+// for the simulated server dialect
+func (c *sqlmock) timeoutError() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.hasDialect && c.dialect == MySQL {
+		return ErrMaxExecutionTime
+	}
+	return ErrStatementTimeout
+}
@@ -0,0 +1,64 @@
+package sqlmock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWillTimeoutAfter(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM reports").WillTimeoutAfter(20 * time.Millisecond)
+	mock.ExpectExec("UPDATE reports").WillTimeoutAfter(0)
+
+	start := time.Now()
+	if _, err = db.Query("SELECT id FROM reports"); err != ErrStatementTimeout {
+		t.Errorf("expected the statement timeout error, but got: %v", err)
+	}
+	if took := time.Since(start); took < 20*time.Millisecond {
+		t.Errorf("expected the query to time out after 20ms, but it took %s", took)
+	}
+
+	mock.WithServerVersion(MySQL, "8.0.32")
+	if _, err = db.Exec("UPDATE reports SET done = 1"); err != ErrMaxExecutionTime {
+		t.Errorf("expected the max execution time error of mysql, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestWillTimeoutAfterContextDeadline(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectExec("UPDATE reports").WillTimeoutAfter(time.Minute)
+	mock.ExpectCancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err = db.ExecContext(ctx, "UPDATE reports SET done = 1"); err != context.DeadlineExceeded {
+		t.Errorf("expected the error of the context, but got: %v", err)
+	}
+	if took := time.Since(start); took > 5*time.Second {
+		t.Errorf("expected the timeout to be cut short by the context, but the statement took %s", took)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
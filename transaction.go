@@ -0,0 +1,65 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// transaction is a database transaction begun on the mock,
+// which may be finished only once
+type transaction struct {
+	sync.Mutex
+	conn   *sqlmock
+	handle string
+	done   bool
+}
+
+// finish marks the transaction as finished, it fails with
+// sql.ErrTxDone if it was already finished
+func (tx *transaction) finish() error {
+	tx.Lock()
+	defer tx.Unlock()
+
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	return nil
+}
+
+func (tx *transaction) Commit() error {
+	if err := tx.finish(); err != nil {
+		return err
+	}
+	return tx.conn.commit(tx.handle)
+}
+
+func (tx *transaction) Rollback() error {
+	if err := tx.finish(); err != nil {
+		return err
+	}
+	return tx.conn.rollback(tx.handle)
+}
+
+func (c *sqlmock) RequireTransactions() {
+	c.Lock()
+	c.txRequired = true
+	c.Unlock()
+}
+
+// checkTransaction rejects a stripped query, which modifies data
+// outside of a transaction, if transactions are required
+func (c *sqlmock) checkTransaction(query string) error {
+	c.Lock()
+	outside := c.txRequired && !c.inTx
+	c.Unlock()
+
+	if !outside {
+		return nil
+	}
+	if verb := modifyingStatement(query); verb != "" {
+		return fmt.Errorf("%s query '%s' was not expected outside of a transaction, since transactions are required", verb, query)
+	}
+	return nil
+}
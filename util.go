@@ -7,7 +7,92 @@ import (
 
 var re = regexp.MustCompile("\\s+")
 
+// isStripped tells whether the query is already stripped, so
+// that it could be used without allocating a stripped copy
+func isStripped(q string) bool {
+	if q == "" {
+		return true
+	}
+	if first, last := q[0], q[len(q)-1]; first <= ' ' || first >= 0x80 || last <= ' ' || last >= 0x80 {
+		return false // leave unicode and control characters to TrimSpace
+	}
+	for i := 0; i < len(q); i++ {
+		switch q[i] {
+		case '\t', '\n', '\f', '\r':
+			return false
+		case ' ':
+			if q[i+1] == ' ' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // strip out new lines and trim spaces
 func stripQuery(q string) (s string) {
+	if isStripped(q) {
+		return q
+	}
 	return strings.TrimSpace(re.ReplaceAllString(q, " "))
 }
+
+var (
+	literalRe      = regexp.MustCompile(`'(?:[^']|'')*'|\$?\b\d+(?:\.\d+)?\b`)
+	placeholdersRe = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+)
+
+// fingerprint identifies queries of the same shape, by replacing
+// literals and numbered placeholders of the stripped query with ?
+// and lists of placeholders, like IN lists or multi row VALUES,
+// with (...)
+func fingerprint(query string) string {
+	return placeholdersRe.ReplaceAllString(literalRe.ReplaceAllString(query, "?"), "(...)")
+}
+
+// editDistance computes levenshtein distance between two strings
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < curr[j] {
+				curr[j] = d
+			}
+			if d := curr[j-1] + 1; d < curr[j] {
+				curr[j] = d
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(t)]
+}
+
+// regexDistance computes the edit distance between the query and
+// the sql regexp with escapes removed, ignoring case
+func regexDistance(re *regexp.Regexp, query string) int {
+	expr := strings.Replace(re.String(), "\\", "", -1)
+	return editDistance(strings.ToLower(query), strings.ToLower(stripQuery(expr)))
+}
+
+var modifyingStatementRe = regexp.MustCompile(`(?i)^\s*(INSERT|UPDATE|DELETE|MERGE|TRUNCATE)\b`)
+
+// modifyingStatement returns the upper cased command of a stripped
+// query, which modifies data, or an empty string otherwise
+func modifyingStatement(query string) string {
+	if m := modifyingStatementRe.FindStringSubmatch(query); m != nil {
+		return strings.ToUpper(m[1])
+	}
+	return ""
+}
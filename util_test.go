@@ -18,4 +18,37 @@ func TestQueryStringStripping(t *testing.T) {
     FROM D
 `, "SELECT c FROM D")
 	assert("UPDATE  (.+) SET  ", "UPDATE (.+) SET")
+	assert("SELECT a,\tb", "SELECT a, b")
+	assert("SELECT 1\r\n", "SELECT 1")
+	assert("SELECT 'é'\u00a0", "SELECT 'é'")
+	assert("SELECT 1", "SELECT 1")
+	assert("", "")
+}
+
+func TestEditDistance(t *testing.T) {
+	assert := func(a, b string, expected int) {
+		if d := editDistance(a, b); d != expected {
+			t.Errorf("Expected distance between '%s' and '%s' to be %d, but got %d", a, b, expected, d)
+		}
+	}
+
+	assert("", "", 0)
+	assert("SELECT", "", 6)
+	assert("", "SELECT", 6)
+	assert("SELECT 1", "SELECT 1", 0)
+	assert("SELECT * FROM users", "SELECT * FROM usres", 2)
+	assert("kitten", "sitting", 3)
+}
+
+func TestFingerprint(t *testing.T) {
+	assert := func(query, expected string) {
+		if fp := fingerprint(query); fp != expected {
+			t.Errorf("Expected fingerprint of '%s' to be '%s', but got '%s'", query, expected, fp)
+		}
+	}
+
+	assert("SELECT * FROM users WHERE id = 5", "SELECT * FROM users WHERE id = ?")
+	assert("SELECT * FROM users WHERE name = 'it''s' AND score > 1.5", "SELECT * FROM users WHERE name = ? AND score > ?")
+	assert("SELECT * FROM users2 WHERE id IN (1, 2, 3)", "SELECT * FROM users2 WHERE id IN (...)")
+	assert("INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4)", "INSERT INTO t (a, b) VALUES (...), (...)")
 }
@@ -0,0 +1,105 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// matches sql regexp parts, which could never match a stripped query
+var unstrippedRe = regexp.MustCompile(`^\^( |\\s)|[\n\t\r\f]|\\[ntrf]| {2}|( |\\s)\$$`)
+
+// matches placeholders of the common drivers: ?, $1, :name and @p1, or
+// any wildcard, which could match a placeholder
+var placeholderRe = regexp.MustCompile(`\?|\$\d|:\w|@\w|\.|\\[SWw]|\[`)
+
+func (c *sqlmock) ValidateColumns() {
+	c.Lock()
+	c.validateColumns = true
+	c.Unlock()
+}
+
+func (c *sqlmock) Validate() error {
+	c.Lock()
+	expected := append([]expectation(nil), c.expected...)
+	raw, columns := c.rawQueries, c.validateColumns
+	c.Unlock()
+
+	var problems []string
+	for i, e := range expected {
+		e.Lock()
+		for _, problem := range validateExpectation(e, raw, columns) {
+			problems = append(problems, fmt.Sprintf("expectation %d %T: %s", i+1, e, problem))
+		}
+		e.Unlock()
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("expectations are not valid:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// validateExpectation finds common mistakes in expectation, raw is
+// set when queries are not stripped, columns when they are validated
+func validateExpectation(e expectation, raw, columns bool) (problems []string) {
+	var sqlRegex *regexp.Regexp
+	var argc int
+	switch t := e.(type) {
+	case *ExpectedPrepare:
+		sqlRegex = t.sqlRegex
+	case *ExpectedExec:
+		sqlRegex, argc = t.sqlRegex, len(t.args)
+	case *ExpectedQuery:
+		sqlRegex, argc = t.sqlRegex, len(t.args)
+		for _, set := range t.sets {
+			if columns {
+				if problem := columnProblem(set); problem != "" {
+					problems = append(problems, problem)
+				}
+			}
+			if rs, ok := set.(*rows); ok {
+				problems = append(problems, rs.problems...)
+				for i, row := range rs.rows {
+					if len(row) != len(rs.cols) {
+						problems = append(problems, fmt.Sprintf("row %d has %d values, but there are %d columns", i+1, len(row), len(rs.cols)))
+					}
+				}
+			}
+		}
+	default:
+		return
+	}
+
+	expr := sqlRegex.String()
+	if !raw && unstrippedRe.MatchString(expr) {
+		problems = append(problems, fmt.Sprintf("sql regexp '%s' expects whitespace, which is stripped from queries, so it may never match", expr))
+	}
+	// only an anchored sql regexp is known to match whole query
+	if argc > 0 && strings.HasSuffix(expr, "$") && !placeholderRe.MatchString(expr) {
+		problems = append(problems, fmt.Sprintf("%d arguments are expected, but sql regexp '%s' matches no placeholders", argc, expr))
+	}
+	return
+}
+
+// columnProblem tells why columns of the rows are not valid, unless
+// the rows allow any columns
+func columnProblem(set driver.Rows) string {
+	if a, ok := set.(interface{ columnsAllowed() bool }); ok && a.columnsAllowed() {
+		return ""
+	}
+
+	cols := set.Columns()
+	if len(cols) == 0 {
+		return "rows have no columns"
+	}
+	seen := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		if seen[col] {
+			return fmt.Sprintf("rows have duplicate column '%s'", col)
+		}
+		seen[col] = true
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO product_viewers").WithArgs(2, 3).WillReturnResult(NewResult(1, 1))
+	mock.ExpectQuery("^SELECT id FROM users WHERE id = \\?$").WithArgs(1).WillReturnRows(NewRows([]string{"id"}))
+	mock.ExpectPrepare("SELECT (.+) FROM users")
+
+	if err = mock.Validate(); err != nil {
+		t.Fatalf("error '%s' was not expected while validating expectations", err)
+	}
+
+	mock.ExpectQuery("SELECT id,\n  name FROM users").
+		WillReturnRows(NewRows([]string{"id", "name"}).FromCSVString("1,bob\n2"))
+	mock.ExpectExec("^DELETE FROM users WHERE id = 5$").WithArgs(5).WillReturnResult(NewResult(0, 1))
+
+	err = mock.Validate()
+	if err == nil {
+		t.Fatal("an error was expected when validating invalid expectations")
+	}
+
+	for _, problem := range []string{
+		"expectation 4 *sqlmock.ExpectedQuery: csv row 2 has 1 values, but there are 2 columns",
+		"expectation 4 *sqlmock.ExpectedQuery: sql regexp 'SELECT id,\n  name FROM users' expects whitespace",
+		"expectation 5 *sqlmock.ExpectedExec: 1 arguments are expected, but sql regexp '^DELETE FROM users WHERE id = 5$' matches no placeholders",
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("expected validation error to report %q, but got: %s", problem, err)
+		}
+	}
+	if strings.Contains(err.Error(), "expectation 1 ") || strings.Contains(err.Error(), "expectation 2 ") {
+		t.Errorf("expected valid expectations not to be reported, but got: %s", err)
+	}
+}
+
+func TestValidateColumns(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	var anyColumns RowsColumnsAllower
+	if !As(NewRows(nil), &anyColumns) {
+		t.Fatal("expected rows to allow any columns")
+	}
+
+	mock.ValidateColumns()
+	mock.ExpectQuery("SELECT 1").WillReturnRows(anyColumns.AllowAnyColumns())
+	mock.ExpectQuery("SELECT id").WillReturnRows(NewRows([]string{"id", "name", "id"}).AddRow(1, "bob", 2))
+	mock.ExpectQuery("SELECT name").WillReturnRows(NewRows(nil))
+
+	err = mock.Validate()
+	expected := "expectations are not valid:\n" +
+		"  - expectation 2 *sqlmock.ExpectedQuery: rows have duplicate column 'id'\n" +
+		"  - expectation 3 *sqlmock.ExpectedQuery: rows have no columns"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error:\n%s\nbut got:\n%v", expected, err)
+	}
+
+	rs, err := db.Query("SELECT 1")
+	if err != nil {
+		t.Errorf("error '%s' was not expected, since the rows allow any columns", err)
+	} else {
+		rs.Close()
+	}
+	_, err = db.Query("SELECT id, name, id FROM users")
+	if err == nil || err.Error() != "query 'SELECT id, name, id FROM users' with args [], rows of the expectation are not valid: rows have duplicate column 'id'" {
+		t.Errorf("expected an error about the duplicate column, but got: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies the database server simulated by the mock
+type Dialect int
+
+// supported database server dialects
+const (
+	MySQL Dialect = iota
+	Postgres
+	SQLite
+)
+
+// versionQuery describes a version or capability detection query
+// together with the single row it is answered by
+type versionQuery struct {
+	sqlRegex string
+	cols     []string
+	values   []driver.Value
+}
+
+func (c *sqlmock) WithServerVersion(dialect Dialect, version string) {
+	var queries []versionQuery
+	switch dialect {
+	case MySQL:
+		queries = []versionQuery{
+			{`(?i)^SELECT (@@(GLOBAL\.|SESSION\.)?VERSION|VERSION\(\))( AS \S+)?;?$`, []string{"VERSION()"}, []driver.Value{version}},
+			{`(?i)^SELECT @@(GLOBAL\.|SESSION\.)?VERSION_COMMENT( AS \S+)?;?$`, []string{"@@version_comment"}, []driver.Value{"MySQL Community Server - GPL"}},
+			{`(?i)^SHOW (GLOBAL |SESSION )?VARIABLES LIKE 'version';?$`, []string{"Variable_name", "Value"}, []driver.Value{"version", version}},
+		}
+	case Postgres:
+		num := postgresVersionNum(version)
+		queries = []versionQuery{
+			{`(?i)^SHOW SERVER_VERSION;?$`, []string{"server_version"}, []driver.Value{version}},
+			{`(?i)^SHOW SERVER_VERSION_NUM;?$`, []string{"server_version_num"}, []driver.Value{num}},
+			{`(?i)^SELECT CURRENT_SETTING\('server_version'\)( AS \S+)?;?$`, []string{"current_setting"}, []driver.Value{version}},
+			{`(?i)^SELECT CURRENT_SETTING\('server_version_num'\)(::int\S*)?( AS \S+)?;?$`, []string{"current_setting"}, []driver.Value{num}},
+			{`(?i)^SELECT (PG_CATALOG\.)?VERSION\(\)( AS \S+)?;?$`, []string{"version"}, []driver.Value{
+				fmt.Sprintf("PostgreSQL %s on x86_64-pc-linux-gnu, compiled by gcc, 64-bit", version)}},
+		}
+	case SQLite:
+		queries = []versionQuery{
+			{`(?i)^SELECT SQLITE_VERSION\(\)( AS \S+)?;?$`, []string{"sqlite_version()"}, []driver.Value{version}},
+		}
+	default:
+		panic(fmt.Sprintf("sqlmock: unknown dialect %d", dialect))
+	}
+
+	c.Lock()
+	c.dialect, c.hasDialect = dialect, true
+	c.Unlock()
+
+	for _, q := range queries {
+		c.IgnoreQueries(q.sqlRegex).WillReturnRows(NewRows(q.cols).AddRow(q.values...))
+	}
+}
+
+// postgresVersionNum converts the version to the number reported
+// as server_version_num, for example 150002 for 15.2 and 90624
+// for 9.6.24
+func postgresVersionNum(version string) string {
+	var parts [3]int
+	for i, p := range strings.SplitN(strings.Fields(version + " ")[0], ".", 3) {
+		parts[i], _ = strconv.Atoi(p)
+	}
+
+	if parts[0] >= 10 {
+		return strconv.Itoa(parts[0]*10000 + parts[1])
+	}
+	return strconv.Itoa(parts[0]*10000 + parts[1]*100 + parts[2])
+}
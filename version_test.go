@@ -0,0 +1,69 @@
+package sqlmock
+
+import (
+	"testing"
+)
+
+func TestWithServerVersion(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	mock.WithServerVersion(MySQL, "8.0.32")
+
+	for _, query := range []string{"SELECT VERSION()", "select @@version", "SELECT @@session.version AS v"} {
+		var version string
+		if err = db.QueryRow(query).Scan(&version); err != nil {
+			t.Errorf("error '%s' was not expected while detecting server version with %s", err, query)
+		} else if version != "8.0.32" {
+			t.Errorf("expected server version 8.0.32 for %s, but got %s", query, version)
+		}
+	}
+
+	var name, value string
+	if err = db.QueryRow("SHOW VARIABLES LIKE 'version'").Scan(&name, &value); err != nil {
+		t.Errorf("error '%s' was not expected while showing version variable", err)
+	} else if name != "version" || value != "8.0.32" {
+		t.Errorf("expected version variable to be 8.0.32, but got %s = %s", name, value)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestWithPostgresServerVersion(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.RequireExpectations(true)
+	mock.WithServerVersion(Postgres, "9.6.24")
+
+	var num int
+	if err = db.QueryRow("SHOW server_version_num").Scan(&num); err != nil {
+		t.Errorf("error '%s' was not expected while detecting server version", err)
+	} else if num != 90624 {
+		t.Errorf("expected server version number 90624, but got %d", num)
+	}
+}
+
+func TestPostgresVersionNum(t *testing.T) {
+	for version, expected := range map[string]string{
+		"15.2":                 "150002",
+		"10.23":                "100023",
+		"9.6.24":               "90624",
+		"14.7 (Debian 14.7-1)": "140007",
+	} {
+		if num := postgresVersionNum(version); num != expected {
+			t.Errorf("expected postgres version %s number to be %s, but got %s", version, expected, num)
+		}
+	}
+}
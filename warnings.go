@@ -0,0 +1,38 @@
+package sqlmock
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (c *sqlmock) Warnings() []string {
+	c.Lock()
+	defer c.Unlock()
+	return append([]string(nil), c.warnings...)
+}
+
+func (c *sqlmock) WarningsAsErrors(strict bool) {
+	c.Lock()
+	c.strictWarnings = strict
+	c.Unlock()
+}
+
+// warnf records a non-fatal issue, must not be called under the mock lock
+func (c *sqlmock) warnf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	c.Lock()
+	c.warnings = append(c.warnings, msg)
+	c.Unlock()
+}
+
+// warningsError returns the error listing warnings,
+// if they are promoted to errors
+func (c *sqlmock) warningsError() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.strictWarnings || len(c.warnings) == 0 {
+		return nil
+	}
+	return fmt.Errorf("there were %d warnings promoted to errors:\n  - %s", len(c.warnings), strings.Join(c.warnings, "\n  - "))
+}
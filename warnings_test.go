@@ -0,0 +1,50 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1)).Reusable()
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("UPDATE users SET name = ?", "bob"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+	if _, err := db.Exec("DELETE FROM users"); err != nil {
+		t.Errorf("error '%s' was not expected while deleting", err)
+	}
+	if _, err := db.Exec("INSERT INTO users(name) VALUES(?)", "bob"); err != nil {
+		t.Errorf("error '%s' was not expected, since expectations are not required", err)
+	}
+
+	warnings := mock.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, but got: %v", warnings)
+	}
+	if !strings.HasPrefix(warnings[0], "call was matched by reusable expectation as a fallback, while next expectation is: ExpectedExec => expecting Exec which:\n  - matches sql: 'DELETE FROM users'") {
+		t.Errorf("unexpected warning about the fallback: %s", warnings[0])
+	}
+	if !strings.HasPrefix(warnings[1], "call to exec 'INSERT INTO users(name) VALUES(?)' query with args [bob] was not expected, tolerated since expectations are not required, did you mean: ExpectedExec => expecting Exec which:\n  - matches sql: 'UPDATE users'") {
+		t.Errorf("unexpected warning about the tolerated call: %s", warnings[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+
+	mock.WarningsAsErrors(true)
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.HasPrefix(err.Error(), "there were 2 warnings promoted to errors:\n  - call was matched by reusable expectation") {
+		t.Errorf("expected warnings to be reported as errors, but got: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package sqlmock
+
+import (
+	"fmt"
+	"time"
+)
+
+// window requires an expectation to be first matched within
+// a duration after the expectation of is first matched, or after
+// the clock of the mock started, if of is nil
+type window struct {
+	of *commonExpectation
+	d  time.Duration
+}
+
+// MatchedWithin requires the expectation e to be first matched within
+// the duration d after the expectation of was first matched, or after
+// the mock was created, if of is nil, measured on the simulated Clock
+// of the mock, so that code maintaining leases or heartbeats could be
+// tested deterministically:
+//
+//	begin := mock.ExpectBegin()
+//	heartbeat := mock.ExpectExec("UPDATE leases SET renewed_at")
+//	sqlmock.MatchedWithin(heartbeat, 10*time.Second, begin)
+//
+// A violated window is reported by ExpectationsWereMet.
+func MatchedWithin(e Expected, d time.Duration, of Expected) {
+	w := &window{d: d}
+	if of != nil {
+		w.of = of.common()
+	}
+	e.common().within(w)
+}
+
+// MustBeCalledWithin requires the query to be first called within the
+// duration d after the mock was created, or after each of the given
+// expectations was first matched, see MatchedWithin, so that scheduling
+// or debouncing of writes could be tested:
+//
+//	flush := mock.ExpectExec("INSERT INTO events").MustBeCalledWithin(time.Second)
+//
+// A violated window is reported by ExpectationsWereMet.
+func (e *ExpectedQuery) MustBeCalledWithin(d time.Duration, after ...Expected) *ExpectedQuery {
+	calledWithin(e, d, after)
+	return e
+}
+
+// MustBeCalledWithin requires the statement to be first called within
+// the duration d after the mock was created, or after each of the given
+// expectations was first matched, see ExpectedQuery.MustBeCalledWithin.
+func (e *ExpectedExec) MustBeCalledWithin(d time.Duration, after ...Expected) *ExpectedExec {
+	calledWithin(e, d, after)
+	return e
+}
+
+// calledWithin requires e to be matched within a window
+// of MatchedWithin after each of the expectations
+func calledWithin(e Expected, d time.Duration, after []Expected) {
+	if len(after) == 0 {
+		MatchedWithin(e, d, nil)
+	}
+	for _, of := range after {
+		MatchedWithin(e, d, of)
+	}
+}
+
+func (e *commonExpectation) within(w *window) {
+	e.Lock()
+	e.windows = append(e.windows, w)
+	e.Unlock()
+}
+
+// firstMatched returns the simulated time the expectation was first
+// matched at, if it was
+func (e *commonExpectation) firstMatched() (time.Time, bool) {
+	e.Lock()
+	defer e.Unlock()
+	return e.matchedAt, e.calls > 0 && !e.matchedAt.IsZero()
+}
+
+// checkWindow verifies that the expectation was matched within its
+// windows, the expectations are locked one at a time, outer is the
+// expectation embedding e
+func (e *commonExpectation) checkWindow(outer expectation) error {
+	e.Lock()
+	windows, clock := e.windows, e.clock
+	e.Unlock()
+	if len(windows) == 0 {
+		return nil
+	}
+
+	at, ok := e.firstMatched()
+	if !ok {
+		return nil // reported as not matched
+	}
+	for _, w := range windows {
+		if w.of == nil {
+			if clock != nil && at.Sub(clock.start) > w.d {
+				return fmt.Errorf("expectation was matched %s after the mock was created, but expected within %s: %s", at.Sub(clock.start), w.d, lockedString(outer))
+			}
+			continue
+		}
+
+		start, started := w.of.firstMatched()
+		switch {
+		case !started:
+			return fmt.Errorf("expectation was matched before the one its window starts with: %s", lockedString(outer))
+		case at.Before(start):
+			return fmt.Errorf("expectation was matched %s before the one its window starts with: %s", start.Sub(at), lockedString(outer))
+		case at.Sub(start) > w.d:
+			return fmt.Errorf("expectation was matched %s after the one its window starts with, but expected within %s: %s", at.Sub(start), w.d, lockedString(outer))
+		}
+	}
+	return nil
+}
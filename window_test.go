@@ -0,0 +1,95 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchedWithin(t *testing.T) {
+	t.Parallel()
+	for _, elapsed := range []time.Duration{10 * time.Second, 11 * time.Second} {
+		db, mock, err := New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		begin := mock.ExpectBegin()
+		heartbeat := mock.ExpectExec("UPDATE leases SET renewed_at").WillReturnResult(NewResult(0, 1))
+		MatchedWithin(heartbeat, 10*time.Second, begin)
+		mock.ExpectCommit()
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+		}
+		mock.Clock().Advance(elapsed)
+		if _, err = tx.Exec("UPDATE leases SET renewed_at = now()"); err != nil {
+			t.Errorf("an error '%s' was not expected while renewing the lease", err)
+		}
+		if err = tx.Commit(); err != nil {
+			t.Errorf("an error '%s' was not expected when committing a transaction", err)
+		}
+
+		err = mock.ExpectationsWereMet()
+		if elapsed <= 10*time.Second && err != nil {
+			t.Errorf("there were unfulfilled expections: %s", err)
+		}
+		if elapsed > 10*time.Second && (err == nil || !strings.HasPrefix(err.Error(), "expectation was matched 11s after the one its window starts with, but expected within 10s: ExpectedExec")) {
+			t.Errorf("expected an error about the late heartbeat, but got: %v", err)
+		}
+		db.Close()
+	}
+}
+
+func TestMustBeCalledWithin(t *testing.T) {
+	t.Parallel()
+	for _, elapsed := range []time.Duration{time.Second, 2 * time.Second} {
+		db, mock, err := New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.MatchExpectationsInOrder(true)
+		first := mock.ExpectExec("INSERT INTO events").MustBeCalledWithin(time.Second).WillReturnResult(NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO events").MustBeCalledWithin(time.Second, first).WillReturnResult(NewResult(2, 1))
+
+		// a debounced writer flushes after the elapsed time each
+		for i := 0; i < 2; i++ {
+			mock.Clock().Advance(elapsed)
+			if _, err = db.Exec("INSERT INTO events VALUES (?)", i); err != nil {
+				t.Errorf("an error '%s' was not expected while flushing events", err)
+			}
+		}
+
+		err = mock.ExpectationsWereMet()
+		if elapsed <= time.Second && err != nil {
+			t.Errorf("there were unfulfilled expections: %s", err)
+		}
+		if elapsed > time.Second && (err == nil || !strings.HasPrefix(err.Error(), "expectation was matched 2s after the mock was created, but expected within 1s: ExpectedExec")) {
+			t.Errorf("expected an error about the late flush, but got: %v", err)
+		}
+		db.Close()
+	}
+}
+
+func TestMatchedWithinMockCreation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	MatchedWithin(mock.ExpectExec("INSERT INTO events").WillReturnResult(NewResult(1, 1)), time.Second, nil)
+
+	mock.Clock().Advance(2 * time.Second)
+	if _, err = db.Exec("INSERT INTO events VALUES (?)", 1); err != nil {
+		t.Errorf("an error '%s' was not expected while flushing events", err)
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.HasPrefix(err.Error(), "expectation was matched 2s after the mock was created, but expected within 1s: ExpectedExec") {
+		t.Errorf("expected an error about the late flush, but got: %v", err)
+	}
+}